@@ -0,0 +1,165 @@
+package quickiedata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ChunkError is the error returned for a single chunk of a bulk request
+type ChunkError struct {
+	IDs []string
+	Err error
+}
+
+func (ce *ChunkError) Error() string {
+	return fmt.Sprintf("chunk %s: %s", strings.Join(ce.IDs, "|"), ce.Err)
+}
+
+func (ce *ChunkError) Unwrap() error {
+	return ce.Err
+}
+
+// BulkError collects the errors of all chunks that failed during a bulk request.
+// Entities from chunks that succeeded are still returned alongside it.
+type BulkError struct {
+	Chunks []*ChunkError
+}
+
+func (be *BulkError) Error() string {
+	if len(be.Chunks) == 1 {
+		return be.Chunks[0].Error()
+	}
+	return fmt.Sprintf("%d chunks failed, first error: %s", len(be.Chunks), be.Chunks[0])
+}
+
+func (be *BulkError) Unwrap() []error {
+	errs := make([]error, 0, len(be.Chunks))
+	for _, chunk := range be.Chunks {
+		errs = append(errs, chunk)
+	}
+	return errs
+}
+
+// FailedIDs returns the ids of every chunk that failed
+func (be *BulkError) FailedIDs() []string {
+	var ids []string
+	for _, chunk := range be.Chunks {
+		ids = append(ids, chunk.IDs...)
+	}
+	return ids
+}
+
+// ChunkIDs removes duplicate ids and splits the rest into chunks of at most size ids
+func ChunkIDs(ids []string, size int) [][]string {
+	if size <= 0 || size > MaxEntitiesPerRequest {
+		size = MaxEntitiesPerRequest
+	}
+
+	var seen = make(map[string]bool)
+	var chunks [][]string
+	var chunk []string
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		chunk = append(chunk, id)
+		if len(chunk) == size {
+			chunks = append(chunks, chunk)
+			chunk = nil
+		}
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// GetEntitiesBulk gets any number of entities by splitting ids into api sized chunks
+// and requesting them in parallel. The results of all successful chunks are merged
+// into one response. If any chunks fail, a *BulkError is returned along with the
+// partial response.
+func (wd *WikidataClient) GetEntitiesBulk(ctx context.Context, ids []string, options *GetEntitiesOptions) (*GetEntitiesResponse, error) {
+	if options == nil {
+		options = NewGetEntitiesOptions()
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("no ids specified")
+	}
+	if err := ValidateEntityIDs(ids); err != nil {
+		return nil, err
+	}
+
+	chunks := ChunkIDs(ids, options.ChunkSize)
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var chunkErrors []*ChunkError
+	result := &GetEntitiesResponse{
+		Entities: make(map[string]*EntityInfo),
+	}
+
+	semaphore := make(chan struct{}, concurrency)
+	for _, chunk := range chunks {
+		wg.Add(1)
+		go func(chunk []string) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				mu.Lock()
+				chunkErrors = append(chunkErrors, &ChunkError{IDs: chunk, Err: ctx.Err()})
+				mu.Unlock()
+				return
+			}
+
+			response, err := wd.GetEntities(ctx, chunk, options)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				chunkErrors = append(chunkErrors, &ChunkError{IDs: chunk, Err: err})
+				return
+			}
+			for key, entity := range response.Entities {
+				result.Entities[key] = entity
+			}
+			result.Success = 1
+		}(chunk)
+	}
+	wg.Wait()
+
+	if len(chunkErrors) > 0 {
+		// keep error order deterministic
+		order := make(map[string]int, len(chunks))
+		for idx, chunk := range chunks {
+			order[chunk[0]] = idx
+		}
+		sort.Slice(chunkErrors, func(i, j int) bool {
+			return order[chunkErrors[i].IDs[0]] < order[chunkErrors[j].IDs[0]]
+		})
+		return result, &BulkError{Chunks: chunkErrors}
+	}
+
+	return result, nil
+}
+
+// GetEntitiesBulkSimple is the simplified version of GetEntitiesBulk
+func (wd *WikidataClient) GetEntitiesBulkSimple(ctx context.Context, ids []string, options *GetEntitiesOptions) (*GetEntitiesSimpleResponse, error) {
+	response, err := wd.GetEntitiesBulk(ctx, ids, options)
+	if response == nil {
+		return nil, err
+	}
+	return response.Simplify(), err
+}
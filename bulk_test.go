@@ -0,0 +1,92 @@
+package quickiedata_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rohfle/quickiedata"
+)
+
+func TestChunkIDs(t *testing.T) {
+	var ids []string
+	for i := 1; i <= 120; i++ {
+		ids = append(ids, fmt.Sprintf("Q%d", i))
+	}
+	ids = append(ids, "Q1", "Q2") // duplicates are dropped
+
+	chunks := quickiedata.ChunkIDs(ids, 0)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	if len(chunks[0]) != 50 || len(chunks[1]) != 50 || len(chunks[2]) != 20 {
+		t.Errorf("unexpected chunk sizes %d %d %d", len(chunks[0]), len(chunks[1]), len(chunks[2]))
+	}
+}
+
+func TestGetEntitiesBulk(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		ids := strings.Split(r.URL.Query().Get("ids"), "|")
+		if len(ids) > quickiedata.MaxEntitiesPerRequest {
+			t.Errorf("chunk too large: %d ids", len(ids))
+		}
+		if ids[0] == "Q51" {
+			w.Write([]byte(`{"error":{"code":"internal","info":"chunk failed"}}`))
+			return
+		}
+		var response = map[string]any{"success": 1}
+		var entities = make(map[string]any)
+		for _, id := range ids {
+			entities[id] = map[string]any{"id": id, "type": "item"}
+		}
+		response["entities"] = entities
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		APIEndpoint: server.URL,
+		Client:      server.Client(),
+	}
+
+	var ids []string
+	for i := 1; i <= 120; i++ {
+		ids = append(ids, fmt.Sprintf("Q%d", i))
+	}
+
+	result, err := wd.GetEntitiesBulk(context.Background(), ids, quickiedata.NewGetEntitiesOptions())
+	if requests.Load() != 3 {
+		t.Errorf("expected 3 requests, got %d", requests.Load())
+	}
+
+	var bulkErr *quickiedata.BulkError
+	if !errors.As(err, &bulkErr) {
+		t.Fatalf("expected BulkError, got %v", err)
+	}
+	if len(bulkErr.Chunks) != 1 || len(bulkErr.FailedIDs()) != 50 {
+		t.Errorf("unexpected failed chunks: %v", bulkErr)
+	}
+	if len(result.Entities) != 70 {
+		t.Errorf("expected 70 entities from successful chunks, got %d", len(result.Entities))
+	}
+	if result.Entities["Q120"] == nil || result.Entities["Q51"] != nil {
+		t.Error("entities merged from wrong chunks")
+	}
+
+	// nil options use the defaults
+	result, err = wd.GetEntitiesBulk(context.Background(), []string{"Q1", "Q2"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Entities) != 2 {
+		t.Errorf("expected 2 entities with default options, got %d", len(result.Entities))
+	}
+}
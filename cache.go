@@ -0,0 +1,300 @@
+package quickiedata
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores response data by key. A ttl of 0 means the value does not expire,
+// although the cache may still evict it.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+}
+
+// CacheSettings configures the optional response cache of a WikidataClient
+type CacheSettings struct {
+	Cache Cache
+	// EntityTTL is how long entities are served from the cache without checking
+	// if they have been modified. Older entities are revalidated by comparing
+	// their revision id, and only refetched if they have changed.
+	EntityTTL time.Duration
+	// SPARQLTTL is how long sparql responses are cached, 0 disables sparql caching
+	SPARQLTTL time.Duration
+}
+
+// cachedEntity is the value stored in the cache for an entity
+type cachedEntity struct {
+	FetchedAt time.Time   `json:"fetched"`
+	Entity    *EntityInfo `json:"entity"`
+}
+
+// entityCacheKey creates a key from the entity id and the options that change the response
+func entityCacheKey(id string, opt *GetEntitiesOptions) string {
+	variant := strings.Join([]string{
+		strings.Join(opt.Languages, "|"),
+		strings.Join(opt.Sitefilter, "|"),
+		strings.Join(opt.Props, "|"),
+	}, ";")
+	if !opt.Redirects {
+		variant += ";noredirects"
+	}
+	if opt.LanguageFallback {
+		variant += ";languagefallback"
+	}
+	hash := sha256.Sum256([]byte(variant))
+	return "entity:" + id + ":" + hex.EncodeToString(hash[:8])
+}
+
+// SPARQLCacheKey creates the cache key of a rendered sparql query
+func SPARQLCacheKey(sparqlQuery string) string {
+	hash := sha256.Sum256([]byte(sparqlQuery))
+	return "sparql:" + hex.EncodeToString(hash[:])
+}
+
+// getEntitiesCached serves fresh entities from the cache, revalidates stale entities
+// and fetches the rest
+func (wd *WikidataClient) getEntitiesCached(ctx context.Context, ids []string, options *GetEntitiesOptions) (*GetEntitiesResponse, error) {
+	settings := wd.Caching
+	result := &GetEntitiesResponse{
+		Entities: make(map[string]*EntityInfo),
+		Success:  1,
+	}
+
+	var stale = make(map[string]*EntityInfo)
+	var uncached []string
+	for _, id := range ids {
+		data, ok := settings.Cache.Get(entityCacheKey(id, options))
+		var cached cachedEntity
+		if !ok || json.Unmarshal(data, &cached) != nil || cached.Entity == nil {
+			uncached = append(uncached, id)
+		} else if time.Since(cached.FetchedAt) <= settings.EntityTTL {
+			result.Entities[id] = cached.Entity
+		} else {
+			stale[id] = cached.Entity
+		}
+	}
+
+	if len(stale) > 0 {
+		// only fetch revision info to check if stale entities have changed
+		var staleIDs []string
+		for id := range stale {
+			staleIDs = append(staleIDs, id)
+		}
+		infoOptions := *options
+		infoOptions.Props = []string{"info"}
+		info, err := wd.fetchEntities(ctx, staleIDs, &infoOptions)
+		if err != nil {
+			return nil, err
+		}
+		for id, entity := range stale {
+			current, exists := info.Entities[id]
+			if exists && current.LastRevID == entity.LastRevID && current.Modified == entity.Modified {
+				result.Entities[id] = entity
+				wd.cacheEntity(id, entity, options)
+			} else {
+				uncached = append(uncached, id)
+			}
+		}
+	}
+
+	if len(uncached) > 0 {
+		// revision info is needed to cache and revalidate entities, and is only returned with the info prop
+		fetchOptions := options
+		if len(options.Props) > 0 && !ValueInSlice("info", options.Props) {
+			withInfo := *options
+			withInfo.Props = append(append([]string{}, options.Props...), "info")
+			fetchOptions = &withInfo
+		}
+		response, err := wd.fetchEntities(ctx, uncached, fetchOptions)
+		if err != nil {
+			return nil, err
+		}
+		result.ServedBy = response.ServedBy
+		for key, entity := range response.Entities {
+			result.Entities[key] = entity
+			wd.cacheEntity(key, entity, options)
+		}
+	}
+
+	return result, nil
+}
+
+func (wd *WikidataClient) cacheEntity(id string, entity *EntityInfo, options *GetEntitiesOptions) {
+	// missing entities have no revision and are not cached
+	if entity == nil || entity.LastRevID == 0 {
+		return
+	}
+	data, err := json.Marshal(&cachedEntity{
+		FetchedAt: time.Now(),
+		Entity:    entity,
+	})
+	if err != nil {
+		DebugLog.Printf("failed to cache entity %s: %s", id, err)
+		return
+	}
+	wd.Caching.Cache.Set(entityCacheKey(id, options), data, 0)
+}
+
+// MemoryCache is an in-memory least recently used cache
+type MemoryCache struct {
+	maxEntries int
+	mu         sync.Mutex
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates a MemoryCache that holds at most maxEntries values
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+func (mc *MemoryCache) Get(key string) ([]byte, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	elem, exists := mc.entries[key]
+	if !exists {
+		return nil, false
+	}
+	entry := elem.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		mc.order.Remove(elem)
+		delete(mc.entries, key)
+		return nil, false
+	}
+	mc.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (mc *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	entry := &memoryCacheEntry{
+		key:   key,
+		value: value,
+	}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+
+	if elem, exists := mc.entries[key]; exists {
+		elem.Value = entry
+		mc.order.MoveToFront(elem)
+		return
+	}
+	mc.entries[key] = mc.order.PushFront(entry)
+
+	for mc.maxEntries > 0 && mc.order.Len() > mc.maxEntries {
+		oldest := mc.order.Back()
+		mc.order.Remove(oldest)
+		delete(mc.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+func (mc *MemoryCache) Delete(key string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if elem, exists := mc.entries[key]; exists {
+		mc.order.Remove(elem)
+		delete(mc.entries, key)
+	}
+}
+
+// Len returns the number of values in the cache
+func (mc *MemoryCache) Len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.order.Len()
+}
+
+// DiskCache stores values as files in a directory, named by the hash of the key
+type DiskCache struct {
+	Dir string
+}
+
+// NewDiskCache creates a DiskCache in dir, creating the directory if needed
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{Dir: dir}, nil
+}
+
+func (dc *DiskCache) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(dc.Dir, hex.EncodeToString(hash[:]))
+}
+
+// Get reads a value from disk. Each file starts with the expiry time in unix nanoseconds.
+func (dc *DiskCache) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(dc.path(key))
+	if err != nil || len(data) < 8 {
+		return nil, false
+	}
+	expires := int64(binary.BigEndian.Uint64(data[:8]))
+	if expires > 0 && time.Now().UnixNano() > expires {
+		os.Remove(dc.path(key))
+		return nil, false
+	}
+	return data[8:], true
+}
+
+func (dc *DiskCache) Set(key string, value []byte, ttl time.Duration) {
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).UnixNano()
+	}
+	data := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(data, uint64(expires))
+	data = append(data, value...)
+
+	// write to a temporary file first so readers never see a partial value
+	path := dc.path(key)
+	tmp, err := os.CreateTemp(dc.Dir, ".tmp-*")
+	if err != nil {
+		DebugLog.Printf("failed to write cache file: %s", err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		DebugLog.Printf("failed to write cache file: %s", err)
+	}
+}
+
+func (dc *DiskCache) Delete(key string) {
+	err := os.Remove(dc.path(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		DebugLog.Printf("failed to delete cache file: %s", err)
+	}
+}
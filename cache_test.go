@@ -0,0 +1,189 @@
+package quickiedata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rohfle/quickiedata"
+)
+
+func TestGetEntitiesCached(t *testing.T) {
+	var revision atomic.Int64
+	revision.Store(100)
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		requests = append(requests, query.Get("props")+":"+query.Get("ids"))
+		var entities = make(map[string]any)
+		for _, id := range strings.Split(query.Get("ids"), "|") {
+			entities[id] = map[string]any{"id": id, "type": "item", "lastrevid": revision.Load(), "modified": "2025-01-01T00:00:00Z"}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": 1, "entities": entities})
+	}))
+	defer server.Close()
+
+	settings := &quickiedata.CacheSettings{
+		Cache:     quickiedata.NewMemoryCache(100),
+		EntityTTL: time.Hour,
+	}
+	wd := &quickiedata.WikidataClient{
+		APIEndpoint: server.URL,
+		Client:      server.Client(),
+		Caching:     settings,
+	}
+	options := quickiedata.NewGetEntitiesOptions()
+	ctx := context.Background()
+
+	if _, err := wd.GetEntities(ctx, []string{"Q1", "Q2"}, options); err != nil {
+		t.Fatal(err)
+	}
+	result, err := wd.GetEntities(ctx, []string{"Q1", "Q2", "Q3"}, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Entities) != 3 || len(requests) != 2 || requests[1] != ":Q3" {
+		t.Errorf("expected only uncached ids to be requested, got %v", requests)
+	}
+
+	// stale entities are revalidated with their revision id
+	settings.EntityTTL = 0
+	requests = nil
+	if _, err := wd.GetEntities(ctx, []string{"Q1"}, options); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 || requests[0] != "info:Q1" {
+		t.Errorf("expected revalidation request only, got %v", requests)
+	}
+
+	revision.Store(101)
+	requests = nil
+	result, err = wd.GetEntities(ctx, []string{"Q1"}, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || requests[1] != ":Q1" || result.Entities["Q1"].LastRevID != 101 {
+		t.Errorf("expected modified entity to be refetched, got %v", requests)
+	}
+}
+
+func TestGetEntitiesCachedWithProps(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		requests = append(requests, query.Get("props")+":"+query.Get("ids"))
+		props := strings.Split(query.Get("props"), "|")
+		var entities = make(map[string]any)
+		for _, id := range strings.Split(query.Get("ids"), "|") {
+			entity := map[string]any{"id": id, "type": "item"}
+			// like the api, revision info is only returned with the info prop
+			if quickiedata.ValueInSlice("info", props) {
+				entity["lastrevid"] = 100
+				entity["modified"] = "2025-01-01T00:00:00Z"
+			}
+			entities[id] = entity
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": 1, "entities": entities})
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		APIEndpoint: server.URL,
+		Client:      server.Client(),
+		Caching: &quickiedata.CacheSettings{
+			Cache:     quickiedata.NewMemoryCache(100),
+			EntityTTL: time.Hour,
+		},
+	}
+	options := quickiedata.NewGetEntitiesOptions()
+	options.Props = []string{"labels"}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := wd.GetEntities(ctx, []string{"Q1"}, options); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(requests) != 1 || requests[0] != "labels|info:Q1" {
+		t.Errorf("expected one request with the info prop, got %v", requests)
+	}
+	if len(options.Props) != 1 {
+		t.Errorf("expected options to be unchanged, got %v", options.Props)
+	}
+}
+
+func TestSPARQLQueryCached(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(testSPARQLResponse))
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		SPARQLEndpoint: server.URL,
+		Client:         server.Client(),
+		Caching: &quickiedata.CacheSettings{
+			Cache:     quickiedata.NewMemoryCache(100),
+			SPARQLTTL: time.Hour,
+		},
+	}
+	query := quickiedata.NewSPARQLQuery()
+	query.Template = "SELECT ?item WHERE { ?item wdt:P31 wd:Q146 }"
+
+	for i := 0; i < 2; i++ {
+		resp, err := wd.SPARQLQuerySimple(context.Background(), query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Results) != 1 {
+			t.Errorf("unexpected results %v", resp.Results)
+		}
+	}
+	query.Limit = 5
+	if _, err := wd.SPARQLQuerySimple(context.Background(), query, nil); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected 2 requests, got %d", requests.Load())
+	}
+}
+
+func TestCaches(t *testing.T) {
+	diskCache, err := quickiedata.NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, cache := range map[string]quickiedata.Cache{
+		"memory": quickiedata.NewMemoryCache(2),
+		"disk":   diskCache,
+	} {
+		cache.Set("a", []byte("1"), 0)
+		cache.Set("b", []byte("2"), time.Nanosecond)
+		time.Sleep(time.Millisecond)
+		if value, ok := cache.Get("a"); !ok || string(value) != "1" {
+			t.Errorf("%s: expected value for a, got %q", name, value)
+		}
+		if _, ok := cache.Get("b"); ok {
+			t.Errorf("%s: expected b to expire", name)
+		}
+		cache.Delete("a")
+		if _, ok := cache.Get("a"); ok {
+			t.Errorf("%s: expected a to be deleted", name)
+		}
+	}
+
+	memoryCache := quickiedata.NewMemoryCache(2)
+	memoryCache.Set("a", []byte("1"), 0)
+	memoryCache.Set("b", []byte("2"), 0)
+	memoryCache.Get("a")
+	memoryCache.Set("c", []byte("3"), 0)
+	if _, ok := memoryCache.Get("b"); ok || memoryCache.Len() != 2 {
+		t.Error("expected least recently used value to be evicted")
+	}
+}
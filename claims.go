@@ -15,18 +15,22 @@ type Claim struct {
 }
 
 type SimpleClaim struct {
-	Type       string                        `json:"type,omitempty"`
-	Rank       string                        `json:"rank,omitempty"`
-	Value      any                           `json:"value,omitempty"`
-	Qualifiers map[string][]*SimpleSnakValue `json:"qualifiers,omitempty"`
+	ID         string                          `json:"id,omitempty"`
+	Type       string                          `json:"type,omitempty"`
+	Rank       string                          `json:"rank,omitempty"`
+	Value      any                             `json:"value,omitempty"`
+	Qualifiers map[string][]*SimpleSnakValue   `json:"qualifiers,omitempty"`
+	References []map[string][]*SimpleSnakValue `json:"references,omitempty"`
 }
 
 func (sc *SimpleClaim) UnmarshalJSON(data []byte) error {
 	var peek struct {
-		Type       string                        `json:"type,omitempty"`
-		Rank       string                        `json:"rank,omitempty"`
-		Value      json.RawMessage               `json:"value,omitempty"`
-		Qualifiers map[string][]*SimpleSnakValue `json:"qualifiers,omitempty"`
+		ID         string                          `json:"id,omitempty"`
+		Type       string                          `json:"type,omitempty"`
+		Rank       string                          `json:"rank,omitempty"`
+		Value      json.RawMessage                 `json:"value,omitempty"`
+		Qualifiers map[string][]*SimpleSnakValue   `json:"qualifiers,omitempty"`
+		References []map[string][]*SimpleSnakValue `json:"references,omitempty"`
 	}
 
 	err := json.Unmarshal(data, &peek)
@@ -34,9 +38,11 @@ func (sc *SimpleClaim) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	sc.ID = peek.ID
 	sc.Type = peek.Type
 	sc.Rank = peek.Rank
 	sc.Qualifiers = peek.Qualifiers
+	sc.References = peek.References
 
 	sc.Value, err = unmarshalSimpleSnakValue(peek.Type, peek.Value)
 	return err
@@ -62,6 +68,63 @@ func (sc *SimpleClaim) GetQualifier(key string) *SimpleSnakValue {
 	return qualifiers[0]
 }
 
+// GetReferenceSnaks returns the values of a property across all references of the claim.
+// References are only kept when simplified with KeepReferences.
+func (sc *SimpleClaim) GetReferenceSnaks(key string) []*SimpleSnakValue {
+	if sc == nil {
+		return nil
+	}
+
+	var snaks []*SimpleSnakValue
+	for _, reference := range sc.References {
+		snaks = append(snaks, reference[key]...)
+	}
+	return snaks
+}
+
+// GetReferenceURLs returns the reference urls (P854) of the claim
+func (sc *SimpleClaim) GetReferenceURLs() []string {
+	var urls []string
+	for _, snak := range sc.GetReferenceSnaks("P854") {
+		if value := snak.ValueAsString(); value != nil {
+			urls = append(urls, *value)
+		}
+	}
+	return urls
+}
+
+// GetStatedIn returns the ids of the works the claim is stated in (P248)
+func (sc *SimpleClaim) GetStatedIn() []string {
+	var ids []string
+	for _, snak := range sc.GetReferenceSnaks("P248") {
+		if value := snak.ValueAsString(); value != nil {
+			ids = append(ids, *value)
+		}
+	}
+	return ids
+}
+
+// GetRetrieved returns the dates the references of the claim were retrieved (P813)
+func (sc *SimpleClaim) GetRetrieved() []*SnakValueTime {
+	var times []*SnakValueTime
+	for _, snak := range sc.GetReferenceSnaks("P813") {
+		if value := snak.ValueAsTime(); value != nil {
+			times = append(times, value)
+		}
+	}
+	return times
+}
+
+// IsSomeValue reports whether the claim has an unknown value, only set when simplified with KeepSpecialValues
+func (sc *SimpleClaim) IsSomeValue() bool {
+	return sc != nil && sc.Value == SomeValue
+}
+
+// IsNoValue reports whether the claim explicitly has no value, only set when simplified with KeepSpecialValues
+func (sc *SimpleClaim) IsNoValue() bool {
+	return sc != nil && sc.Value == NoValue
+}
+
 func (sc *SimpleClaim) ValueAsString() *string {
 	if sc == nil {
 		return nil
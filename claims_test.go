@@ -0,0 +1,46 @@
+package quickiedata_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rohfle/quickiedata"
+)
+
+func TestSimpleClaimReferences(t *testing.T) {
+	options := quickiedata.NewSimplifyOptions()
+	options.KeepReferences = true
+
+	item := quickiedata.SimplifyEntityWithOptions(loadTestEntity(t, "Q22002395"), options).(*quickiedata.SimpleItem)
+	claim := item.GetClaim("P123")
+	if urls := claim.GetReferenceURLs(); len(urls) == 0 || urls[0] != "https://inventaire.io/entity/isbn:9783839412213/Gewissensbisse" {
+		t.Errorf("unexpected reference urls %v", urls)
+	}
+	if retrieved := claim.GetRetrieved(); len(retrieved) == 0 || retrieved[0].Time != "+2016-01-10T00:00:00Z" {
+		t.Errorf("unexpected retrieved dates %v", retrieved)
+	}
+
+	item = quickiedata.SimplifyEntityWithOptions(loadTestEntity(t, "Q2112"), options).(*quickiedata.SimpleItem)
+	claim = item.GetClaim("P214")
+	if statedIn := claim.GetStatedIn(); len(statedIn) != 1 || statedIn[0] != "Q54919" {
+		t.Errorf("unexpected stated in %v", statedIn)
+	}
+
+	// references round trip through json
+	data, err := json.Marshal(claim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTrip quickiedata.SimpleClaim
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	if statedIn := roundTrip.GetStatedIn(); len(statedIn) != 1 || statedIn[0] != "Q54919" || len(roundTrip.GetRetrieved()) != 1 {
+		t.Errorf("references lost in round trip: %s", data)
+	}
+
+	var nilClaim *quickiedata.SimpleClaim
+	if nilClaim.GetReferenceURLs() != nil {
+		t.Error("expected nil for nil claim")
+	}
+}
@@ -3,12 +3,14 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/rohfle/nicehttp"
 	"github.com/rohfle/quickiedata"
@@ -33,6 +35,92 @@ func parseArray(val string) ([]string, error) {
 	return strArr, nil
 }
 
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed while rendering results: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// checkOutput returns an error if output is not json, geojson (if allowed) or a record output format
+func checkOutput(output string, allowGeoJSON bool) error {
+	if output == "json" || (output == "geojson" && allowGeoJSON) || isRecordOutput(output) {
+		return nil
+	}
+	formats := []string{"json"}
+	if allowGeoJSON {
+		formats = append(formats, "geojson")
+	}
+	for _, format := range quickiedata.ListOfOutputFormats {
+		formats = append(formats, string(format))
+	}
+	return fmt.Errorf("unknown output %q (must be one of %s)", output, strings.Join(formats, ", "))
+}
+
+func isRecordOutput(output string) bool {
+	return quickiedata.ValueInSlice(quickiedata.OutputFormat(output), quickiedata.ListOfOutputFormats)
+}
+
+// readIDs reads entity ids separated by whitespace or commas
+func readIDs(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return strings.FieldsFunc(string(data), func(c rune) bool {
+		return c == ',' || unicode.IsSpace(c)
+	}), nil
+}
+
+// withFormattedClaims converts a simplified entity to a map with its claims, and the claims of
+// its forms and senses, replaced by formatted claims
+func withFormattedClaims(ctx context.Context, entity any, options *quickiedata.FormatOptions) (map[string]any, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var output map[string]any
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+
+	replaceClaims := func(target map[string]any, claims map[string][]*quickiedata.SimpleClaim) {
+		if _, exists := target["claims"]; exists {
+			target["claims"] = quickiedata.FormatClaims(ctx, claims, options)
+		}
+	}
+	switch e := entity.(type) {
+	case *quickiedata.SimpleItem:
+		replaceClaims(output, e.Claims)
+	case *quickiedata.SimpleProperty:
+		replaceClaims(output, e.Claims)
+	case *quickiedata.SimpleLexeme:
+		if e.Language != "" {
+			output["language"] = quickiedata.FormatContext(ctx, e.Language, options)
+		}
+		if e.LexicalCategory != "" {
+			output["category"] = quickiedata.FormatContext(ctx, e.LexicalCategory, options)
+		}
+		if forms, ok := output["forms"].([]any); ok && len(forms) == len(e.Forms) {
+			for idx, form := range forms {
+				if target, ok := form.(map[string]any); ok {
+					replaceClaims(target, e.Forms[idx].Claims)
+				}
+			}
+		}
+		if senses, ok := output["senses"].([]any); ok && len(senses) == len(e.Senses) {
+			for idx, sense := range senses {
+				if target, ok := sense.(map[string]any); ok {
+					replaceClaims(target, e.Senses[idx].Claims)
+				}
+			}
+		}
+	}
+	return output, nil
+}
+
 func main() {
 	ctx := context.Background()
 	wd := quickiedata.NewClient(&nicehttp.Settings{
@@ -56,6 +144,11 @@ func main() {
 
 	var offset int
 	var limit int
+	var timeout int64
+	var method string
+	var queryAll bool
+	var pageSize int64
+	var output string
 
 	// Query command
 	var queryCmd = &cobra.Command{
@@ -108,6 +201,49 @@ func main() {
 
 			query.Template = queryText
 			options := quickiedata.NewSPARQLQueryOptions()
+			options.Timeout = timeout
+			options.Method = quickiedata.SPARQLMethod(method)
+			if err := checkOutput(output, true); err != nil {
+				return err
+			}
+			if queryAll {
+				// fetch every page of results
+				pageOptions := quickiedata.NewSPARQLPagesOptions()
+				pageOptions.PageSize = pageSize
+				if !cmd.Flags().Changed("limit") {
+					query.Limit = 0
+				}
+				// json lines are streamed, other outputs need every page first
+				stream := output == "json" || output == "jsonl"
+				collected := &quickiedata.SPARQLSimpleResponse{}
+				encoder := json.NewEncoder(os.Stdout)
+				for row, err := range wd.SPARQLQueryPages(ctx, query, options, pageOptions) {
+					if err != nil {
+						return fmt.Errorf("sparql request failed:\nquery:\n  %s\noptions: %+v\nerror: %w",
+							strings.ReplaceAll(queryText, "\n", "\n  "),
+							options,
+							err,
+						)
+					}
+					if !stream {
+						collected.Results = append(collected.Results, row)
+						continue
+					}
+					values := make(map[string]any)
+					for key, value := range row {
+						values[key] = value.Value
+					}
+					if err := encoder.Encode(values); err != nil {
+						return fmt.Errorf("failed while rendering results for %q: %w", query, err)
+					}
+				}
+				if output == "geojson" {
+					return printJSON(collected.ToGeoJSON(nil))
+				} else if !stream {
+					return quickiedata.WriteSPARQLResults(os.Stdout, quickiedata.OutputFormat(output), collected)
+				}
+				return nil
+			}
 			resp, err := wd.SPARQLQuerySimple(ctx, query, options)
 			if err != nil {
 				return fmt.Errorf("sparql request failed:\nquery:\n  %s\noptions: %+v\nerror: %w",
@@ -117,6 +253,12 @@ func main() {
 				)
 			}
 
+			if output == "geojson" {
+				return printJSON(resp.ToGeoJSON(nil))
+			} else if isRecordOutput(output) {
+				return quickiedata.WriteSPARQLResults(os.Stdout, quickiedata.OutputFormat(output), resp)
+			}
+
 			if len(resp.Results) == 0 {
 				fmt.Println("no results")
 				return nil
@@ -131,25 +273,50 @@ func main() {
 		},
 	}
 	queryCmd.Flags().IntVar(&offset, "offset", 0, "Offset for results")
-	queryCmd.Flags().IntVar(&limit, "limit", 10, "Limit for results")
+	queryCmd.Flags().IntVar(&limit, "limit", 10, "Limit for results (maximum number of rows when used with --all)")
+	queryCmd.Flags().BoolVar(&queryAll, "all", false, "Fetch all pages of results, printed as JSON Lines unless --output is set")
+	queryCmd.Flags().Int64Var(&pageSize, "page-size", 1000, "Number of rows per page when used with --all")
+	queryCmd.Flags().Int64Var(&timeout, "timeout", -1, "Query timeout in seconds")
+	queryCmd.Flags().StringVarP(&output, "output", "o", "json", "Output format (json, geojson, table, csv, tsv, jsonl or yaml)")
+	queryCmd.Flags().StringVar(&output, "format", "json", "Output format, same as --output")
+	queryCmd.Flags().StringVar(&method, "method", string(quickiedata.SPARQLMethodPostRaw), "How to send the query (get, post-form or post)")
 	queryCmd.SilenceUsage = true
 
 	// Search command
 	var entityType string
+	var searchAll bool
+	var maxResults int
 	var searchCmd = &cobra.Command{
 		Use:   "search [term]",
 		Short: "Search for entities by term",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			query := args[0]
+			if err := checkOutput(output, false); err != nil {
+				return err
+			}
 			options := quickiedata.NewSearchEntitiesOptions()
 			options.Language = language
 			options.EntityType = entityType
 			options.Offset = int64(offset)
 			options.Limit = int64(limit)
-			result, err := wd.SearchEntities(ctx, query, options)
-			if err != nil {
-				return fmt.Errorf("failed while searching for %q: %w", query, err)
+			var result []*quickiedata.SearchResult
+			if searchAll {
+				for item, err := range wd.SearchEntitiesAll(ctx, query, options, int64(maxResults)) {
+					if err != nil {
+						return fmt.Errorf("failed while searching for %q: %w", query, err)
+					}
+					result = append(result, item)
+				}
+			} else {
+				var err error
+				result, err = wd.SearchEntities(ctx, query, options)
+				if err != nil {
+					return fmt.Errorf("failed while searching for %q: %w", query, err)
+				}
+			}
+			if isRecordOutput(output) {
+				return quickiedata.WriteSearchResults(os.Stdout, quickiedata.OutputFormat(output), result)
 			}
 			if len(result) == 0 {
 				fmt.Println("no results")
@@ -165,51 +332,137 @@ func main() {
 	}
 	searchCmd.Flags().StringVar(&entityType, "type", "item", "Entity type to search for (item or property)")
 	searchCmd.Flags().IntVar(&offset, "offset", 0, "Offset for results")
-	searchCmd.Flags().IntVar(&limit, "limit", 10, "Limit for results")
+	searchCmd.Flags().IntVar(&limit, "limit", 10, "Limit for results (page size when used with --all)")
+	searchCmd.Flags().BoolVar(&searchAll, "all", false, "Follow search-continue to fetch all pages of results")
+	searchCmd.Flags().IntVar(&maxResults, "max", 0, "Maximum number of results when used with --all (0 for no maximum)")
+	searchCmd.Flags().StringVarP(&output, "output", "o", "json", "Output format (json, table, csv, tsv, jsonl or yaml)")
 	searchCmd.SilenceUsage = true
 
 	var sitefilter string
 	var props string
 	var rawMode bool
+	var geoJSONClaims string
+	var resolveLabels bool
+	var languageFallback bool
+	var simplifyOptions = quickiedata.NewSimplifyOptions()
+	// printEntities prints the entities of a get request in the chosen output format
+	printEntities := func(ctx context.Context, result *quickiedata.GetEntitiesResponse) error {
+		if rawMode {
+			return printJSON(result)
+		}
+		simpleResult := result.SimplifyWithOptions(simplifyOptions)
+
+		formatOptions := quickiedata.NewFormatOptions()
+		formatOptions.Language = language
+		if resolveLabels {
+			var entities []any
+			for _, entity := range simpleResult.Entities {
+				entities = append(entities, entity)
+			}
+			resolverOptions := quickiedata.NewLabelResolverOptions()
+			resolverOptions.Fallback = languageFallback
+			labels := quickiedata.NewLabelResolver(wd, resolverOptions)
+			// request all the labels up front so they are fetched in full batches
+			if _, err := labels.Labels(ctx, quickiedata.ReferencedIDs(entities...), []string{language}); err != nil {
+				return fmt.Errorf("failed while resolving labels: %w", err)
+			}
+			formatOptions.LabelResolver = labels
+			formatOptions.ShowIDs = true
+		}
+
+		if output == "geojson" {
+			geoOptions := quickiedata.NewGeoJSONOptions()
+			geoOptions.Languages = []string{language}
+			geoOptions.FormatOptions = formatOptions
+			if geoJSONClaims != "" {
+				geoOptions.Claims = quickiedata.SplitAndTrim(geoJSONClaims, ",")
+			}
+			return printJSON(simpleResult.ToGeoJSON(geoOptions))
+		}
+
+		if isRecordOutput(output) {
+			return quickiedata.WriteEntities(os.Stdout, quickiedata.OutputFormat(output), simpleResult, formatOptions)
+		}
+
+		if resolveLabels {
+			entities := make(map[string]any, len(simpleResult.Entities))
+			for id, entity := range simpleResult.Entities {
+				var err error
+				entities[id], err = withFormattedClaims(ctx, entity, formatOptions)
+				if err != nil {
+					return fmt.Errorf("failed while rendering %s: %w", id, err)
+				}
+			}
+			return printJSON(map[string]any{"entities": entities})
+		}
+		return printJSON(simpleResult)
+	}
 	var getCmd = &cobra.Command{
 		Use:   "get [id1 id2 ...]",
-		Short: "Fetch entity data for given IDs",
-		Args:  cobra.ExactArgs(1),
+		Short: "Fetch entity data for given IDs, read from stdin if none are given",
+		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			wikidataIDs := args
+			if len(wikidataIDs) == 0 {
+				var err error
+				wikidataIDs, err = readIDs(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("failed while reading ids from stdin: %w", err)
+				}
+				if len(wikidataIDs) == 0 {
+					return fmt.Errorf("no ids given as arguments or on stdin")
+				}
+			}
+			if err := checkOutput(output, true); err != nil {
+				return err
+			}
+			if rawMode && output != "json" {
+				return fmt.Errorf("--raw can only be used with json output")
+			}
+			if rawMode && resolveLabels {
+				return fmt.Errorf("--raw cannot be used with --resolve-labels")
+			}
 			options := quickiedata.NewGetEntitiesOptions()
 			options.Languages = []string{language}
+			options.LanguageFallback = languageFallback
 			if sitefilter != "" {
 				options.Sitefilter = quickiedata.SplitAndTrim(sitefilter, ",")
 			}
 			if props != "" {
 				options.Props = quickiedata.SplitAndTrim(props, ",")
 			}
-			result, err := wd.GetEntities(ctx, wikidataIDs, options)
-			if err != nil {
+			result, err := wd.GetEntitiesBulk(ctx, wikidataIDs, options)
+			// print the entities of the chunks that succeeded, then report the ids that failed
+			var bulkErr *quickiedata.BulkError
+			if errors.As(err, &bulkErr) {
+				if err := printEntities(ctx, result); err != nil {
+					return err
+				}
+				return fmt.Errorf("failed while retrieving %s: %w", bulkErr.FailedIDs(), err)
+			} else if err != nil {
 				return fmt.Errorf("failed while retrieving %s: %w", wikidataIDs, err)
 			}
-
-			var data []byte
-			if rawMode {
-				data, err = json.MarshalIndent(result, "", "  ")
-			} else {
-				simpleResult := result.Simplify()
-				data, err = json.MarshalIndent(simpleResult, "", "  ")
-			}
-			if err != nil {
-				return fmt.Errorf("failed while rendering results for %q: %w", wikidataIDs, err)
-			}
-			fmt.Println(string(data))
-			return nil
+			return printEntities(ctx, result)
 		},
 	}
 	getCmd.Flags().StringVar(&sitefilter, "sitefilter", "", "Filter sitelinks by site (e.g. enwiki,enwikiquote)")
 	getCmd.Flags().StringVar(&props, "props", "", "Properties to fetch (e.g. labels,descriptions,claims,sitelinks)")
 	getCmd.Flags().BoolVar(&rawMode, "raw", false, "Output data without simplification")
+	getCmd.Flags().StringVarP(&output, "output", "o", "json", "Output format (json, geojson, table, csv, tsv, jsonl or yaml)")
+	getCmd.Flags().StringVar(&output, "format", "json", "Output format, same as --output")
+	getCmd.Flags().StringVar(&geoJSONClaims, "claims", "", "Claims to add as feature properties with --output geojson (e.g. P31,P17)")
+	getCmd.Flags().BoolVar(&simplifyOptions.TruthyOnly, "truthy", false, "Only keep preferred claims, or normal claims if there are no preferred claims")
+	getCmd.Flags().BoolVar(&simplifyOptions.KeepReferences, "references", false, "Keep claim references")
+	getCmd.Flags().BoolVar(&simplifyOptions.KeepClaimIDs, "claim-ids", false, "Keep claim ids")
+	getCmd.Flags().BoolVar(&simplifyOptions.KeepSpecialValues, "special-values", false, "Keep somevalue and novalue claims")
+	getCmd.Flags().BoolVar(&simplifyOptions.DropEmpty, "drop-empty", false, "Drop properties without claims")
+	getCmd.Flags().BoolVar(&languageFallback, "language-fallback", false, "Return labels, descriptions and aliases in a fallback language if missing (e.g. de for de-ch)")
+	getCmd.Flags().BoolVar(&resolveLabels, "resolve-labels", false, "Show labels next to the item and property ids in claims and qualifiers")
 	getCmd.SilenceUsage = true
 
 	rootCmd.AddCommand(queryCmd, searchCmd, getCmd)
 
-	rootCmd.Execute()
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,274 @@
+package quickiedata
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// TimePrecision is the precision of a wikidata time value
+type TimePrecision int
+
+const (
+	PrecisionBillionYears         TimePrecision = 0
+	PrecisionHundredMillionYears  TimePrecision = 1
+	PrecisionTenMillionYears      TimePrecision = 2
+	PrecisionMillionYears         TimePrecision = 3
+	PrecisionHundredThousandYears TimePrecision = 4
+	PrecisionTenThousandYears     TimePrecision = 5
+	PrecisionMillennium           TimePrecision = 6
+	PrecisionCentury              TimePrecision = 7
+	PrecisionDecade               TimePrecision = 8
+	PrecisionYear                 TimePrecision = 9
+	PrecisionMonth                TimePrecision = 10
+	PrecisionDay                  TimePrecision = 11
+	PrecisionHour                 TimePrecision = 12
+	PrecisionMinute               TimePrecision = 13
+	PrecisionSecond               TimePrecision = 14
+)
+
+const (
+	CalendarGregorian = "Q1985727"
+	CalendarJulian    = "Q1985786"
+)
+
+// maxTimeYear is roughly the largest year that time.Time can represent
+const maxTimeYear = 292_000_000_000
+
+var wikidataTimePattern = regexp.MustCompile(`^([+-]?\d+)-(\d{2})-(\d{2})T(\d{2}):(\d{2}):(\d{2})Z$`)
+
+// ParsedTime is a wikidata time value split into its parts
+type ParsedTime struct {
+	// Year uses historical numbering as wikidata does, so -1 is 1 BCE and there is no year 0
+	Year int64
+	// Month and Day are 0 if not known in a raw time value. Simplified time values replace
+	// unknown months and days with 1, so use Precision to tell which parts are known.
+	Month     int
+	Day       int
+	Hour      int
+	Minute    int
+	Second    int
+	Precision TimePrecision
+	// Timezone is the offset from UTC in minutes
+	Timezone int
+	// Calendar is the id of the calendar model, eg Q1985727 (gregorian)
+	Calendar string
+}
+
+// ParseTime parses a wikidata time value such as +1990-11-00T00:00:00Z
+func ParseTime(s *SnakValueTime) (*ParsedTime, error) {
+	if s == nil {
+		return nil, fmt.Errorf("time is nil")
+	}
+	match := wikidataTimePattern.FindStringSubmatch(s.Time)
+	if match == nil {
+		return nil, fmt.Errorf("invalid wikidata time '%s'", s.Time)
+	}
+
+	year, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wikidata time '%s': %w", s.Time, err)
+	}
+	var parts [5]int
+	for idx := range parts {
+		// always two digits so cannot fail
+		parts[idx], _ = strconv.Atoi(match[idx+2])
+	}
+
+	calendar := GetWikidataIDFromURL(s.CalendarModel)
+	if calendar == "" {
+		calendar = CalendarGregorian
+	}
+
+	return &ParsedTime{
+		Year:      year,
+		Month:     parts[0],
+		Day:       parts[1],
+		Hour:      parts[2],
+		Minute:    parts[3],
+		Second:    parts[4],
+		Precision: TimePrecision(s.Precision),
+		Timezone:  s.Timezone,
+		Calendar:  calendar,
+	}, nil
+}
+
+// Parse parses the time value, see ParseTime
+func (s *SnakValueTime) Parse() (*ParsedTime, error) {
+	return ParseTime(s)
+}
+
+// ToTime converts the time value to a gregorian time.Time, see ParsedTime.ToTime
+func (s *SnakValueTime) ToTime() (time.Time, error) {
+	parsed, err := ParseTime(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parsed.ToTime()
+}
+
+// Bounds returns the start and end of the time value, see ParsedTime.Bounds
+func (s *SnakValueTime) Bounds() (time.Time, time.Time, error) {
+	parsed, err := ParseTime(s)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return parsed.Bounds()
+}
+
+// astronomicalYear converts a historical year to the numbering used by time.Time, where 0 is 1 BCE
+func astronomicalYear(year int64) int64 {
+	if year < 0 {
+		return year + 1
+	}
+	return year
+}
+
+// ToTime returns the time as a proleptic gregorian time.Time in the time value's timezone.
+// Julian dates with day precision or better are converted to gregorian. Unknown months and
+// days are treated as the first. An error is returned if the year cannot be represented.
+func (pt *ParsedTime) ToTime() (time.Time, error) {
+	if pt.Year > maxTimeYear || pt.Year < -maxTimeYear {
+		return time.Time{}, fmt.Errorf("year %d cannot be represented as time.Time", pt.Year)
+	}
+
+	year := int(astronomicalYear(pt.Year))
+	month := max(pt.Month, 1)
+	day := max(pt.Day, 1)
+
+	var t time.Time
+	if pt.Calendar == CalendarJulian && pt.Precision >= PrecisionDay {
+		days := julianToJulianDayNumber(int64(year), month, day) - unixEpochJulianDayNumber
+		t = time.Unix(days*86400, 0).UTC()
+		t = t.Add(time.Duration(pt.Hour)*time.Hour + time.Duration(pt.Minute)*time.Minute + time.Duration(pt.Second)*time.Second)
+	} else {
+		t = time.Date(year, time.Month(month), day, pt.Hour, pt.Minute, pt.Second, 0, time.UTC)
+	}
+
+	if pt.Timezone != 0 {
+		t = t.In(time.FixedZone("", pt.Timezone*60))
+	}
+	return t, nil
+}
+
+// Bounds returns the start (inclusive) and end (exclusive) of the period covered by the
+// time value's precision. For example a precision of century for 1901 covers 1901 to 2001
+// and a precision of month for March 1990 covers 1990-03-01 to 1990-04-01.
+func (pt *ParsedTime) Bounds() (time.Time, time.Time, error) {
+	start := *pt
+	var end ParsedTime
+
+	switch {
+	case pt.Precision >= PrecisionSecond:
+		end = start
+		end.Second++
+	case pt.Precision == PrecisionMinute:
+		start.Second = 0
+		end = start
+		end.Minute++
+	case pt.Precision == PrecisionHour:
+		start.Minute, start.Second = 0, 0
+		end = start
+		end.Hour++
+	case pt.Precision == PrecisionDay:
+		start.Hour, start.Minute, start.Second = 0, 0, 0
+		end = start
+		end.Day = max(end.Day, 1) + 1
+	case pt.Precision == PrecisionMonth:
+		start.Day, start.Hour, start.Minute, start.Second = 1, 0, 0, 0
+		end = start
+		end.Month = max(end.Month, 1) + 1
+	default:
+		startYear, endYear := yearBounds(pt.Year, pt.Precision)
+		start = ParsedTime{Year: startYear, Month: 1, Day: 1, Precision: PrecisionYear, Timezone: pt.Timezone, Calendar: pt.Calendar}
+		end = start
+		end.Year = endYear
+	}
+
+	startTime, err := start.ToTime()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	// time.Date normalises overflowing fields such as month 13
+	endTime, err := end.ToTime()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return startTime, endTime, nil
+}
+
+// yearBounds returns the first year and the year after the last year of the period
+// covered by a year based precision, using historical year numbering
+func yearBounds(year int64, precision TimePrecision) (int64, int64) {
+	if precision >= PrecisionYear {
+		return year, nextYear(year)
+	}
+
+	var size int64 = 1
+	for p := precision; p < PrecisionYear; p++ {
+		size *= 10
+	}
+
+	switch precision {
+	case PrecisionCentury, PrecisionMillennium:
+		// the 20th century is 1901 to 2000, and the 1st century BCE is 100 BCE to 1 BCE
+		if year > 0 {
+			n := (year - 1) / size
+			return n*size + 1, nextYear((n + 1) * size)
+		}
+		n := (-year - 1) / size
+		return -(n + 1) * size, nextYear(-(n*size + 1))
+	default:
+		// decades and larger periods are rounded down, eg the 1990s are 1990 to 1999
+		if year > 0 {
+			n := year / size
+			return max(n*size, 1), (n + 1) * size
+		}
+		n := -year / size
+		return -(n*size + size - 1), nextYear(-max(n*size, 1))
+	}
+}
+
+// nextYear returns the following year, skipping year 0
+func nextYear(year int64) int64 {
+	if year == -1 {
+		return 1
+	}
+	return year + 1
+}
+
+// Compare returns -1, 0 or 1 depending on whether pt starts before, at the same time or after other
+func (pt *ParsedTime) Compare(other *ParsedTime) int {
+	a, _, errA := pt.Bounds()
+	b, _, errB := other.Bounds()
+	if errA == nil && errB == nil {
+		return a.Compare(b)
+	}
+	// years too large for time.Time are compared by year only
+	switch {
+	case pt.Year < other.Year:
+		return -1
+	case pt.Year > other.Year:
+		return 1
+	}
+	return 0
+}
+
+const unixEpochJulianDayNumber = 2440588
+
+// julianToJulianDayNumber converts a julian calendar date with an astronomical year to a julian day number
+func julianToJulianDayNumber(year int64, month int, day int) int64 {
+	a := int64(14-month) / 12
+	y := year + 4800 - a
+	m := int64(month) + 12*a - 3
+	return int64(day) + floorDiv(153*m+2, 5) + 365*y + floorDiv(y, 4) - 32083
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,154 @@
+package quickiedata_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rohfle/quickiedata"
+)
+
+func TestParseTime(t *testing.T) {
+	parsed, err := quickiedata.ParseTime(&quickiedata.SnakValueTime{
+		Time:          "-0044-03-15T00:00:00Z",
+		Precision:     11,
+		CalendarModel: "http://www.wikidata.org/entity/Q1985786",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Year != -44 || parsed.Month != 3 || parsed.Day != 15 {
+		t.Errorf("unexpected date %+v", parsed)
+	}
+	if parsed.Precision != quickiedata.PrecisionDay || parsed.Calendar != quickiedata.CalendarJulian {
+		t.Errorf("unexpected precision or calendar %+v", parsed)
+	}
+
+	if _, err := quickiedata.ParseTime(&quickiedata.SnakValueTime{Time: "1990"}); err == nil {
+		t.Error("expected error for invalid time")
+	}
+}
+
+func TestSnakValueTimeToTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    *quickiedata.SnakValueTime
+		expected time.Time
+	}{
+		{
+			name:     "gregorian",
+			value:    &quickiedata.SnakValueTime{Time: "+1990-11-01T00:00:00Z", Precision: 11},
+			expected: time.Date(1990, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "unknown month and day",
+			value:    &quickiedata.SnakValueTime{Time: "+1990-00-00T00:00:00Z", Precision: 9},
+			expected: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "julian",
+			value:    &quickiedata.SnakValueTime{Time: "+1582-10-05T00:00:00Z", Precision: 11, CalendarModel: "http://www.wikidata.org/entity/Q1985786"},
+			expected: time.Date(1582, 10, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "before common era",
+			value:    &quickiedata.SnakValueTime{Time: "-0001-01-01T00:00:00Z", Precision: 9},
+			expected: time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		result, err := test.value.ToTime()
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+		} else if !result.Equal(test.expected) {
+			t.Errorf("%s: expected %s got %s", test.name, test.expected, result)
+		}
+	}
+
+	if _, err := (&quickiedata.SnakValueTime{Time: "-13798000000-00-00T00:00:00Z", Precision: 0}).ToTime(); err != nil {
+		t.Errorf("expected age of universe to fit in time.Time: %s", err)
+	}
+}
+
+func TestSnakValueTimeBounds(t *testing.T) {
+	tests := []struct {
+		name  string
+		value *quickiedata.SnakValueTime
+		start time.Time
+		end   time.Time
+	}{
+		{
+			name:  "month",
+			value: &quickiedata.SnakValueTime{Time: "+1990-12-00T00:00:00Z", Precision: 10},
+			start: time.Date(1990, 12, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(1991, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "decade",
+			value: &quickiedata.SnakValueTime{Time: "+1994-00-00T00:00:00Z", Precision: 8},
+			start: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "century",
+			value: &quickiedata.SnakValueTime{Time: "+2000-00-00T00:00:00Z", Precision: 7},
+			start: time.Date(1901, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "millennium",
+			value: &quickiedata.SnakValueTime{Time: "+1500-00-00T00:00:00Z", Precision: 6},
+			start: time.Date(1001, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// the 1st century BCE is 100 BCE to 1 BCE, year 0 in time.Time is 1 BCE
+			name:  "century before common era",
+			value: &quickiedata.SnakValueTime{Time: "-0050-00-00T00:00:00Z", Precision: 7},
+			start: time.Date(-99, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		start, end, err := test.value.Bounds()
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if !start.Equal(test.start) || !end.Equal(test.end) {
+			t.Errorf("%s: expected %s to %s got %s to %s", test.name, test.start, test.end, start, end)
+		}
+	}
+}
+
+func TestParsedTimeCompare(t *testing.T) {
+	parse := func(value string, precision int) *quickiedata.ParsedTime {
+		parsed, err := quickiedata.ParseTime(&quickiedata.SnakValueTime{Time: value, Precision: precision})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	if parse("-0100-00-00T00:00:00Z", 9).Compare(parse("+0001-00-00T00:00:00Z", 9)) != -1 {
+		t.Error("expected 100 BCE to be before 1 CE")
+	}
+	if parse("+1990-05-01T00:00:00Z", 11).Compare(parse("+1990-00-00T00:00:00Z", 9)) != 1 {
+		t.Error("expected May 1990 to be after the start of 1990")
+	}
+	if parse("-13798000000-00-00T00:00:00Z", 0).Compare(parse("-13798000000-00-00T00:00:00Z", 0)) != 0 {
+		t.Error("expected equal times to compare equal")
+	}
+}
+
+func TestSnakValueTimeGetDate(t *testing.T) {
+	date := (&quickiedata.SnakValueTime{Time: "-0500-00-00T00:00:00Z", Precision: 9}).GetDate()
+	if date == nil || *date != "-0500" {
+		t.Errorf("unexpected date %v", date)
+	}
+	year := (&quickiedata.SnakValueTime{Time: "+1990-11-01T00:00:00Z", Precision: 11}).GetYear()
+	if year == nil || *year != 1990 {
+		t.Errorf("unexpected year %v", year)
+	}
+}
@@ -0,0 +1,269 @@
+package quickiedata
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DecodeError is returned when a sparql value cannot be stored in a struct field
+type DecodeError struct {
+	Row   int
+	Var   string
+	Field string
+	Value any
+	Err   error
+}
+
+func (de *DecodeError) Error() string {
+	return fmt.Sprintf("row %d: cannot decode ?%s value %#v into field %s: %s", de.Row, de.Var, de.Value, de.Field, de.Err)
+}
+
+func (de *DecodeError) Unwrap() error {
+	return de.Err
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var simpleBindingValueType = reflect.TypeOf(&SimpleBindingValue{})
+
+// Decode stores the results in v, which must be a pointer to a slice of structs or
+// struct pointers. Variables are mapped to fields with the `sparql:"name"` tag, or the
+// field name if there is no tag. Fields tagged with `sparql:"-"` are skipped and missing
+// variables leave fields as their zero value.
+//
+// Supported field types are string, bool, integers, floats, time.Time for dateTime
+// literals, *SimpleBindingValue and pointers to any of these.
+func (resp *SPARQLSimpleResponse) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("decode target must be a pointer to a slice, not %T", v)
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("decode target must be a slice of structs, not %s", slice.Type())
+	}
+
+	fields := sparqlStructFields(structType)
+	output := reflect.MakeSlice(slice.Type(), 0, len(resp.Results))
+	for idx, row := range resp.Results {
+		item := reflect.New(structType).Elem()
+		for _, field := range fields {
+			value, exists := row[field.varName]
+			if !exists || value == nil {
+				continue
+			}
+			if err := setBindingField(item.FieldByIndex(field.index), value); err != nil {
+				return &DecodeError{
+					Row:   idx,
+					Var:   field.varName,
+					Field: structType.Name() + "." + field.name,
+					Value: value.Value,
+					Err:   err,
+				}
+			}
+		}
+		if elemType.Kind() == reflect.Pointer {
+			output = reflect.Append(output, item.Addr())
+		} else {
+			output = reflect.Append(output, item)
+		}
+	}
+	slice.Set(output)
+	return nil
+}
+
+// Decode simplifies the results and stores them in v, see SPARQLSimpleResponse.Decode
+func (results *SPARQLResponse) Decode(v any) error {
+	return results.Simplify().Decode(v)
+}
+
+// SPARQLQueryInto runs a query and decodes the results into a slice of T
+func SPARQLQueryInto[T any](ctx context.Context, wd *WikidataClient, query *SPARQLQuery, options *GetSPARQLQueryOptions) ([]T, error) {
+	response, err := wd.SPARQLQuerySimple(ctx, query, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var output []T
+	if err := response.Decode(&output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+type sparqlField struct {
+	name    string
+	varName string
+	index   []int
+}
+
+func sparqlStructFields(structType reflect.Type) []sparqlField {
+	var fields []sparqlField
+	for _, field := range reflect.VisibleFields(structType) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		varName := field.Tag.Get("sparql")
+		if varName == "-" {
+			continue
+		} else if varName == "" {
+			varName = field.Name
+		}
+		fields = append(fields, sparqlField{
+			name:    field.Name,
+			varName: varName,
+			index:   field.Index,
+		})
+	}
+	return fields
+}
+
+var xsdDateTimeYearPattern = regexp.MustCompile(`^([+-]?\d{4,})(-.*)$`)
+
+// parseXSDDateTime parses an xsd:dateTime, including the signed and extended years used by the
+// query service for dates outside 1-9999. Years follow XSD 1.1 numbering, where 0 is 1 BCE,
+// which is the same as time.Time.
+func parseXSDDateTime(s string) (time.Time, error) {
+	match := xsdDateTimeYearPattern.FindStringSubmatch(s)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("invalid dateTime '%s'", s)
+	}
+	year, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil || year > maxTimeYear || year < -maxTimeYear {
+		return time.Time{}, fmt.Errorf("invalid dateTime '%s'", s)
+	}
+	// parse the rest with a leap year in place of the year, so 29 february is accepted
+	t, err := time.Parse(time.RFC3339Nano, "2000"+match[2])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid dateTime '%s': %w", s, err)
+	}
+	result := time.Date(int(year), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if result.Month() != t.Month() || result.Day() != t.Day() {
+		// time.Date normalises days past the end of the month, eg 29 february in a non leap year
+		return time.Time{}, fmt.Errorf("invalid dateTime '%s': day out of range", s)
+	}
+	return result, nil
+}
+
+func setBindingField(field reflect.Value, value *SimpleBindingValue) error {
+	if field.Type() == simpleBindingValueType {
+		field.Set(reflect.ValueOf(value))
+		return nil
+	}
+	if field.Kind() == reflect.Pointer {
+		target := reflect.New(field.Type().Elem())
+		if err := setBindingField(target.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(target)
+		return nil
+	}
+
+	if field.Type() == timeType {
+		s, ok := value.Value.(string)
+		if !ok {
+			return fmt.Errorf("expected dateTime, got %T", value.Value)
+		}
+		t, err := parseXSDDateTime(s)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		switch v := value.Value.(type) {
+		case string:
+			field.SetString(v)
+		case int64:
+			field.SetString(strconv.FormatInt(v, 10))
+		case float64:
+			field.SetString(strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			field.SetString(strconv.FormatBool(v))
+		default:
+			return fmt.Errorf("unexpected %T value", value.Value)
+		}
+	case reflect.Bool:
+		switch v := value.Value.(type) {
+		case bool:
+			field.SetBool(v)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			field.SetBool(b)
+		default:
+			return fmt.Errorf("expected boolean, got %T", value.Value)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch v := value.Value.(type) {
+		case int64:
+			n = v
+		case string:
+			// literals with other numeric datatypes are left as strings
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return err
+			}
+			n = parsed
+		default:
+			return fmt.Errorf("expected integer, got %T", value.Value)
+		}
+		if field.OverflowInt(n) {
+			return fmt.Errorf("%d overflows %s", n, field.Type())
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n int64
+		switch v := value.Value.(type) {
+		case int64:
+			n = v
+		case string:
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return err
+			}
+			n = parsed
+		default:
+			return fmt.Errorf("expected integer, got %T", value.Value)
+		}
+		if n < 0 || field.OverflowUint(uint64(n)) {
+			return fmt.Errorf("%d overflows %s", n, field.Type())
+		}
+		field.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch v := value.Value.(type) {
+		case float64:
+			f = v
+		case int64:
+			f = float64(v)
+		case string:
+			// decimal and double literals are left as strings
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return err
+			}
+			f = parsed
+		default:
+			return fmt.Errorf("expected float, got %T", value.Value)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
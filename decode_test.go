@@ -0,0 +1,101 @@
+package quickiedata_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rohfle/quickiedata"
+)
+
+type testCatRow struct {
+	Item      string    `sparql:"item"`
+	ItemLabel string    `sparql:"itemLabel"`
+	Count     int       `sparql:"count"`
+	Flag      *bool     `sparql:"flag"`
+	Ratio     float64   `sparql:"ratio"`
+	Born      time.Time `sparql:"born"`
+	Ignored   string    `sparql:"-"`
+}
+
+func TestSPARQLDecode(t *testing.T) {
+	var response quickiedata.SPARQLResponse
+	if err := json.Unmarshal([]byte(testSPARQLStreamResponse), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []testCatRow
+	if err := response.Simplify().Decode(&rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Item != "Q146" || rows[0].ItemLabel != "house cat" || rows[0].Count != 42 || rows[0].Flag == nil || !*rows[0].Flag {
+		t.Errorf("unexpected first row %+v", rows[0])
+	}
+	if rows[1].Ratio != 0.5 || rows[1].Flag != nil || !rows[1].Born.Equal(time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected second row %+v", rows[1])
+	}
+
+	var pointerRows []*testCatRow
+	if err := response.Decode(&pointerRows); err != nil {
+		t.Fatal(err)
+	}
+	if len(pointerRows) != 2 || pointerRows[0].Item != "Q146" {
+		t.Errorf("unexpected pointer rows %v", pointerRows)
+	}
+
+	var badRows []struct {
+		ItemLabel int `sparql:"itemLabel"`
+	}
+	err := response.Simplify().Decode(&badRows)
+	var decodeErr *quickiedata.DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Var != "itemLabel" || decodeErr.Row != 0 {
+		t.Errorf("expected decode error for itemLabel, got %v", err)
+	}
+
+	if err := response.Simplify().Decode(rows); err == nil {
+		t.Error("expected error for non pointer target")
+	}
+}
+
+func TestSPARQLDecodeDateTimes(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"1990-01-01T00:00:00Z", time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"+1990-01-01T12:30:00Z", time.Date(1990, 1, 1, 12, 30, 0, 0, time.UTC)},
+		{"-0043-03-15T00:00:00Z", time.Date(-43, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"-13798000000-01-01T00:00:00Z", time.Date(-13798000000, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"12024-02-29T00:00:00Z", time.Date(12024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		resp := &quickiedata.SPARQLSimpleResponse{
+			Results: []map[string]*quickiedata.SimpleBindingValue{
+				{"born": {Value: test.value}},
+			},
+		}
+		var rows []testCatRow
+		if err := resp.Decode(&rows); err != nil {
+			t.Errorf("%s: %s", test.value, err)
+		} else if !rows[0].Born.Equal(test.expected) {
+			t.Errorf("%s: expected %s, got %s", test.value, test.expected, rows[0].Born)
+		}
+	}
+
+	for _, value := range []string{"not a date", "+2023-02-29T00:00:00Z", "1900-02-29T00:00:00Z"} {
+		resp := &quickiedata.SPARQLSimpleResponse{
+			Results: []map[string]*quickiedata.SimpleBindingValue{
+				{"born": {Value: value}},
+			},
+		}
+		var rows []testCatRow
+		if err := resp.Decode(&rows); err == nil {
+			t.Errorf("%s: expected error for invalid dateTime", value)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+// Package dump streams entities from Wikidata JSON dumps such as latest-all.json.gz
+package dump
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/rohfle/quickiedata"
+)
+
+// ClaimFilter matches entities that have a claim for Property with the entity id Value
+type ClaimFilter struct {
+	Property string
+	Value    string
+}
+
+type Options struct {
+	// Types only keeps entities of these types, eg item or property
+	Types []string
+	// IDs only keeps entities with these ids
+	IDs map[string]bool
+	// Claims only keeps entities that match at least one of the claim filters
+	Claims []ClaimFilter
+	// LineFilter is an extra filter run on the raw json line before it is decoded
+	LineFilter func(line []byte) bool
+	// Simplify runs SimplifyEntity on every entity and stores the result in Entity.Simple
+	Simplify bool
+	// Workers is the number of goroutines decoding lines
+	Workers int
+	// StartOffset is the uncompressed byte offset to resume reading from,
+	// usually the NextOffset of the last entity processed
+	StartOffset int64
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Workers: 4,
+	}
+}
+
+// Entity is a single entity read from a dump
+type Entity struct {
+	// Offset is the uncompressed byte offset of the start of the line
+	Offset int64
+	// NextOffset is the uncompressed byte offset of the line after this entity
+	NextOffset int64
+	Entity     *quickiedata.EntityInfo
+	// Simple is only set if Options.Simplify is set
+	Simple any
+}
+
+// LineError is returned when a line of the dump cannot be decoded
+type LineError struct {
+	Offset int64
+	Err    error
+}
+
+func (le *LineError) Error() string {
+	return fmt.Sprintf("line at offset %d: %s", le.Offset, le.Err)
+}
+
+func (le *LineError) Unwrap() error {
+	return le.Err
+}
+
+// Open opens a dump file, decompressing it based on the .gz or .bz2 extension
+func Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &readCloser{Reader: gz, closers: []io.Closer{gz, file}}, nil
+	case strings.HasSuffix(path, ".bz2"):
+		return &readCloser{Reader: bzip2.NewReader(file), closers: []io.Closer{file}}, nil
+	default:
+		return file, nil
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (rc *readCloser) Close() error {
+	var firstErr error
+	for _, closer := range rc.closers {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Reader reads entities line by line from an uncompressed dump stream
+type Reader struct {
+	r       io.Reader
+	options *Options
+}
+
+// NewReader creates a Reader from an uncompressed dump stream, see Open for compressed dumps
+func NewReader(r io.Reader, options *Options) *Reader {
+	if options == nil {
+		options = NewOptions()
+	}
+	return &Reader{
+		r:       r,
+		options: options,
+	}
+}
+
+type line struct {
+	offset int64
+	next   int64
+	data   []byte
+	result chan *result
+}
+
+type result struct {
+	entity *Entity
+	err    error
+}
+
+// Entities iterates over the entities in the dump that match the filters, in the order
+// they appear in the dump. Iteration stops after the first error.
+func (rd *Reader) Entities(ctx context.Context) iter.Seq2[*Entity, error] {
+	return func(yield func(*Entity, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		workers := rd.options.Workers
+		if workers <= 0 {
+			workers = 1
+		}
+
+		jobs := make(chan *line, workers)
+		// ordered holds each line's result channel in dump order
+		ordered := make(chan chan *result, workers*4)
+		readErr := make(chan error, 1)
+
+		go func() {
+			defer close(jobs)
+			defer close(ordered)
+			readErr <- rd.readLines(ctx, func(l *line) bool {
+				select {
+				case ordered <- l.result:
+				case <-ctx.Done():
+					return false
+				}
+				select {
+				case jobs <- l:
+				case <-ctx.Done():
+					return false
+				}
+				return true
+			})
+		}()
+
+		for i := 0; i < workers; i++ {
+			go func() {
+				for l := range jobs {
+					entity, err := rd.decodeLine(l)
+					l.result <- &result{entity: entity, err: err}
+				}
+			}()
+		}
+
+		for resultChan := range ordered {
+			var res *result
+			select {
+			case res = <-resultChan:
+			case <-ctx.Done():
+				yield(nil, ctx.Err())
+				return
+			}
+			if res.err != nil {
+				yield(nil, res.err)
+				return
+			}
+			if res.entity == nil {
+				continue // filtered out
+			}
+			if !yield(res.entity, nil) {
+				return
+			}
+		}
+
+		if err := <-readErr; err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// readLines calls send for every line that passes the cheap filters
+func (rd *Reader) readLines(ctx context.Context, send func(*line) bool) error {
+	offset := rd.options.StartOffset
+	if offset > 0 {
+		if _, err := io.CopyN(io.Discard, rd.r, offset); err != nil {
+			return err
+		}
+	}
+
+	br := bufio.NewReaderSize(rd.r, 1<<20)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data, err := br.ReadBytes('\n')
+		start := offset
+		offset += int64(len(data))
+		if len(data) > 0 {
+			trimmed := bytes.TrimRight(data, ",\r\n\t ")
+			if len(trimmed) > 1 && trimmed[0] == '{' && rd.matchLine(trimmed) {
+				l := &line{
+					offset: start,
+					next:   offset,
+					data:   trimmed,
+					result: make(chan *result, 1),
+				}
+				if !send(l) {
+					return ctx.Err()
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// headerPattern finds the entity type and id before the first nested object.
+// Dumps put these fields first, so the rest of the line does not need to be parsed.
+var headerPattern = regexp.MustCompile(`"(type|id)":"([^"]*)"`)
+
+// matchLine applies the type and id filters using the start of the line and the
+// claim filters using a substring check, before any json decoding
+func (rd *Reader) matchLine(data []byte) bool {
+	opt := rd.options
+	if len(opt.Types) > 0 || len(opt.IDs) > 0 {
+		header := data[1:]
+		if idx := bytes.IndexByte(header, '{'); idx >= 0 {
+			header = header[:idx]
+		}
+		var entityType, entityID string
+		for _, match := range headerPattern.FindAllSubmatch(header, -1) {
+			if string(match[1]) == "type" {
+				entityType = string(match[2])
+			} else {
+				entityID = string(match[2])
+			}
+		}
+		if entityType == "" || entityID == "" {
+			// unusual field order, fall back to decoding the top level fields
+			var peek struct {
+				Type string `json:"type"`
+				ID   string `json:"id"`
+			}
+			if json.Unmarshal(data, &peek) != nil {
+				return true // let the full decode report the error
+			}
+			entityType, entityID = peek.Type, peek.ID
+		}
+		if len(opt.Types) > 0 && !quickiedata.ValueInSlice(entityType, opt.Types) {
+			return false
+		}
+		if len(opt.IDs) > 0 && !opt.IDs[entityID] {
+			return false
+		}
+	}
+
+	if len(opt.Claims) > 0 {
+		var found bool
+		for _, filter := range opt.Claims {
+			if bytes.Contains(data, []byte(`"`+filter.Property+`"`)) && bytes.Contains(data, []byte(`"`+filter.Value+`"`)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if opt.LineFilter != nil && !opt.LineFilter(data) {
+		return false
+	}
+	return true
+}
+
+// decodeLine decodes a line, returning nil if it does not match the claim filters
+func (rd *Reader) decodeLine(l *line) (*Entity, error) {
+	var entity quickiedata.EntityInfo
+	if err := json.Unmarshal(l.data, &entity); err != nil {
+		return nil, &LineError{Offset: l.offset, Err: err}
+	}
+
+	if len(rd.options.Claims) > 0 && !matchClaims(&entity, rd.options.Claims) {
+		return nil, nil
+	}
+
+	output := &Entity{
+		Offset:     l.offset,
+		NextOffset: l.next,
+		Entity:     &entity,
+	}
+	if rd.options.Simplify {
+		output.Simple = quickiedata.SimplifyEntity(&entity)
+	}
+	return output, nil
+}
+
+func matchClaims(entity *quickiedata.EntityInfo, filters []ClaimFilter) bool {
+	for _, filter := range filters {
+		for _, claim := range entity.Claims[filter.Property] {
+			if claim.MainSnak == nil || claim.MainSnak.DataValue == nil {
+				continue
+			}
+			if value := claim.MainSnak.DataValue.ValueAsEntity(); value != nil && value.GetID() == filter.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
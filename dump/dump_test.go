@@ -0,0 +1,127 @@
+package dump_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rohfle/quickiedata"
+	"github.com/rohfle/quickiedata/dump"
+)
+
+const testDump = "testdata/sample.json.bz2"
+
+func readAll(t *testing.T, r io.Reader, options *dump.Options) []*dump.Entity {
+	var entities []*dump.Entity
+	for entity, err := range dump.NewReader(r, options).Entities(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func ids(entities []*dump.Entity) []string {
+	var out []string
+	for _, entity := range entities {
+		out = append(out, entity.Entity.ID)
+	}
+	return out
+}
+
+func TestReader(t *testing.T) {
+	rc, err := dump.Open(testDump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same dump compressed with gzip
+	gzPath := filepath.Join(t.TempDir(), "sample.json.gz")
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(raw)
+	gz.Close()
+	if err := os.WriteFile(gzPath, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{testDump, gzPath} {
+		rc, err := dump.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		options := dump.NewOptions()
+		options.Simplify = true
+		entities := readAll(t, rc, options)
+		rc.Close()
+
+		got := ids(entities)
+		expected := []string{"Q19180293", "Q970917", "Q4115189", "L525"}
+		if len(got) != len(expected) {
+			t.Fatalf("%s: expected %v, got %v", path, expected, got)
+		}
+		for idx := range expected {
+			if got[idx] != expected[idx] {
+				t.Errorf("%s: expected %v in dump order, got %v", path, expected, got)
+			}
+		}
+		if _, ok := entities[3].Simple.(*quickiedata.SimpleLexeme); !ok {
+			t.Errorf("%s: expected simplified lexeme, got %T", path, entities[3].Simple)
+		}
+		if entities[0].Offset != 2 || entities[1].Offset != entities[0].NextOffset {
+			t.Errorf("%s: unexpected offsets %d %d %d", path, entities[0].Offset, entities[0].NextOffset, entities[1].Offset)
+		}
+	}
+
+	// resume from the offset after the second entity
+	options := dump.NewOptions()
+	all := readAll(t, bytes.NewReader(raw), options)
+	options.StartOffset = all[1].NextOffset
+	resumed := readAll(t, bytes.NewReader(raw), options)
+	if got := ids(resumed); len(got) != 2 || got[0] != "Q4115189" || resumed[0].Offset != all[2].Offset {
+		t.Errorf("unexpected resumed entities %v", got)
+	}
+}
+
+func TestReaderFilters(t *testing.T) {
+	rc, err := dump.Open(testDump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(rc)
+	rc.Close()
+
+	options := dump.NewOptions()
+	options.Types = []string{"item"}
+	if got := ids(readAll(t, bytes.NewReader(raw), options)); len(got) != 3 {
+		t.Errorf("expected 3 items, got %v", got)
+	}
+
+	options = dump.NewOptions()
+	options.IDs = map[string]bool{"Q970917": true, "L525": true}
+	if got := ids(readAll(t, bytes.NewReader(raw), options)); len(got) != 2 || got[0] != "Q970917" {
+		t.Errorf("unexpected entities filtered by id %v", got)
+	}
+
+	options = dump.NewOptions()
+	options.Claims = []dump.ClaimFilter{{Property: "P135", Value: "Q213454"}}
+	if got := ids(readAll(t, bytes.NewReader(raw), options)); len(got) != 1 || got[0] != "Q4115189" {
+		t.Errorf("unexpected entities filtered by claim %v", got)
+	}
+
+	// value appears in the line but not as a claim of the property
+	options.Claims = []dump.ClaimFilter{{Property: "P569", Value: "Q213454"}}
+	if got := ids(readAll(t, bytes.NewReader(raw), options)); len(got) != 0 {
+		t.Errorf("expected no entities, got %v", got)
+	}
+}
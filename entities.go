@@ -152,13 +152,25 @@ func (s *SimpleItem) GetClaim(key string) *SimpleClaim {
 }
 
 func (s *SimpleItem) GetRelatedIDsFromClaims(claimIDs []string) []string {
-	if s == nil || len(claimIDs) == 0 {
+	if s == nil {
+		return nil
+	}
+	return relatedIDsFromClaims(s.Claims, claimIDs, false)
+}
+
+// relatedIDsFromClaims returns the string values of the claims with one of claimIDs. If entitiesOnly
+// is set, only claims with an entity type are used, so identifiers that look like ids are skipped.
+func relatedIDsFromClaims(claims map[string][]*SimpleClaim, claimIDs []string, entitiesOnly bool) []string {
+	if len(claimIDs) == 0 {
 		return nil
 	}
 
 	var relatedIDs []string
 	for _, claim := range claimIDs {
-		for _, item := range s.GetClaims(claim) {
+		for _, item := range claims[claim] {
+			if entitiesOnly && !SimpleTypeIsEntity(item.Type) {
+				continue
+			}
 			wikidataID := item.ValueAsString()
 			if wikidataID != nil {
 				relatedIDs = append(relatedIDs, *wikidataID)
@@ -226,7 +238,58 @@ func (s *SimpleLexeme) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// GetLemma returns the lemma in the first of languages that has one, following the language
+// fallback chains, or an empty string if there is none
+func (s *SimpleLexeme) GetLemma(languages ...string) string {
+	if s == nil {
+		return ""
+	}
+	return getTermWithFallback(s.Lemmas, languages)
+}
+
+// GetForm returns the form with the id, eg L525-F1
+func (s *SimpleLexeme) GetForm(id string) *SimpleForm {
+	if s == nil {
+		return nil
+	}
+	for _, form := range s.Forms {
+		if form.ID == id {
+			return form
+		}
+	}
+	return nil
+}
+
+// GetSense returns the sense with the id, eg L525-S1
+func (s *SimpleLexeme) GetSense(id string) *SimpleSense {
+	if s == nil {
+		return nil
+	}
+	for _, sense := range s.Senses {
+		if sense.ID == id {
+			return sense
+		}
+	}
+	return nil
+}
+
+// FormsWithFeatures returns the forms that have all of the grammatical features,
+// for example Q146786 (plural)
+func (s *SimpleLexeme) FormsWithFeatures(features ...string) []*SimpleForm {
+	if s == nil {
+		return nil
+	}
+	var forms []*SimpleForm
+	for _, form := range s.Forms {
+		if form.HasFeatures(features...) {
+			forms = append(forms, form)
+		}
+	}
+	return forms
+}
+
 type SimpleForm struct {
+	ID                  string                    `json:"id,omitempty"`
 	Representations     map[string]string         `json:"representations,omitempty"`
 	GrammaticalFeatures []string                  `json:"features,omitempty"`
 	Claims              map[string][]*SimpleClaim `json:"claims,omitempty"`
@@ -243,6 +306,19 @@ func (s *SimpleForm) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// HasFeatures reports whether the form has all of the grammatical features
+func (s *SimpleForm) HasFeatures(features ...string) bool {
+	if s == nil {
+		return false
+	}
+	for _, feature := range features {
+		if !ValueInSlice(feature, s.GrammaticalFeatures) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *SimpleForm) GetClaims(key string) []*SimpleClaim {
 	if s == nil {
 		return nil
@@ -262,6 +338,7 @@ func (s *SimpleForm) GetClaim(key string) *SimpleClaim {
 }
 
 type SimpleSense struct {
+	ID      string                    `json:"id,omitempty"`
 	Glosses map[string]string         `json:"glosses,omitempty"`
 	Claims  map[string][]*SimpleClaim `json:"claims,omitempty"`
 }
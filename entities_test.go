@@ -0,0 +1,40 @@
+package quickiedata_test
+
+import (
+	"testing"
+
+	"github.com/rohfle/quickiedata"
+)
+
+func TestSimpleLexemeHelpers(t *testing.T) {
+	entity := loadTestEntity(t, "L525")
+	response := &quickiedata.GetEntityResponse{Entity: entity}
+	simple := response.Simplify()
+
+	lexeme := simple.GetEntityAsLexeme("L525")
+	if lexeme.GetLemma("fr") != "maison" || lexeme.GetLemma("de") != "" {
+		t.Errorf("unexpected lemmas %v", lexeme.Lemmas)
+	}
+	if form := lexeme.GetForm("L525-F1"); form == nil || form.Representations["fr"] != "maisons" {
+		t.Errorf("unexpected form %v", form)
+	}
+	if sense := simple.GetEntityAsSense("L525-S1"); sense == nil || sense.ID != "L525-S1" {
+		t.Errorf("unexpected sense %v", sense)
+	}
+	if forms := lexeme.FormsWithFeatures("Q146786"); len(forms) != 1 || forms[0].ID != "L525-F1" {
+		t.Errorf("unexpected plural forms %v", forms)
+	}
+	if forms := lexeme.FormsWithFeatures("Q146786", "Q110786"); len(forms) != 0 {
+		t.Errorf("expected no forms with both features, got %v", forms)
+	}
+
+	multi := (&quickiedata.GetEntitiesResponse{
+		Entities: map[string]*quickiedata.EntityInfo{"L525": entity},
+	}).Simplify()
+	if form := multi.GetEntityAsForm("L525-F2"); form == nil || form.Representations["fr"] != "maison" {
+		t.Errorf("unexpected form from lexeme %v", form)
+	}
+	if multi.GetEntityAsForm("L1-F1") != nil {
+		t.Error("expected nil for missing lexeme")
+	}
+}
@@ -0,0 +1,202 @@
+package quickiedata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxErrorBodyExcerpt is the number of bytes of a failed response body kept in HTTPError
+const maxErrorBodyExcerpt = 512
+
+// HTTPError is returned when a request completes with a non-success http status
+type HTTPError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	// RetryAfter is the duration requested by the Retry-After header, or 0 if not set
+	RetryAfter time.Duration
+	// Body is the start of the response body, useful for debugging html error pages
+	Body string
+}
+
+func (he *HTTPError) Error() string {
+	if he.RetryAfter > 0 {
+		return fmt.Sprintf("request returned status: %s (retry after %s)", he.Status, he.RetryAfter)
+	}
+	return fmt.Sprintf("request returned status: %s", he.Status)
+}
+
+// Temporary reports whether the request may succeed if retried later
+func (he *HTTPError) Temporary() bool {
+	switch he.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// newHTTPError reads the start of the response body and creates an HTTPError from resp
+func newHTTPError(resp *http.Response) *HTTPError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyExcerpt))
+	herr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RetryAfter: parseRetryAfter(resp.Header),
+		Body:       string(body),
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		herr.URL = resp.Request.URL.String()
+	}
+	return herr
+}
+
+func parseRetryAfter(header http.Header) time.Duration {
+	val := header.Get("Retry-After")
+	if val == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(val); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(val); err == nil {
+		if wait := time.Until(t); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// ResponseError is an error returned in the body of a wikidata api response
+type ResponseError struct {
+	Code  string `json:"code"`
+	Info  string `json:"info"`
+	Extra string `json:"extra"`
+	// Star is the help text returned in the "*" field
+	Star string `json:"*,omitempty"`
+	// Lag and Host are only set for maxlag errors
+	Lag  float64 `json:"lag,omitempty"`
+	Host string  `json:"host,omitempty"`
+	// ServedBy is the server that handled the request
+	ServedBy string `json:"servedby,omitempty"`
+}
+
+func (re *ResponseError) Error() string {
+	return re.Info
+}
+
+// MaxLagError is returned when the api refuses a request because database
+// replication lag is higher than the maxlag parameter
+type MaxLagError struct {
+	*ResponseError
+	// RetryAfter is the duration requested by the Retry-After header
+	RetryAfter time.Duration
+}
+
+func (me *MaxLagError) Error() string {
+	return fmt.Sprintf("maxlag exceeded: %s", me.Info)
+}
+
+func (me *MaxLagError) Unwrap() error {
+	return me.ResponseError
+}
+
+// Temporary reports whether the request may succeed if retried later
+func (me *MaxLagError) Temporary() bool {
+	return true
+}
+
+// checkAPIResponse returns a typed error if the api response body contains an error
+func checkAPIResponse(resp *http.Response, body []byte) error {
+	var peek struct {
+		Error    *ResponseError `json:"error"`
+		ServedBy string         `json:"servedby"`
+	}
+	if err := json.Unmarshal(body, &peek); err != nil || peek.Error == nil {
+		return nil
+	}
+
+	peek.Error.ServedBy = peek.ServedBy
+	if peek.Error.Code == "maxlag" {
+		return &MaxLagError{
+			ResponseError: peek.Error,
+			RetryAfter:    parseRetryAfter(resp.Header),
+		}
+	}
+	return peek.Error
+}
+
+// SPARQLErrorKind categorises errors returned by the sparql endpoint
+type SPARQLErrorKind string
+
+const (
+	SPARQLErrorTimeout SPARQLErrorKind = "timeout"
+	SPARQLErrorSyntax  SPARQLErrorKind = "syntax"
+	SPARQLErrorOther   SPARQLErrorKind = "other"
+)
+
+// SPARQLError is returned when the sparql endpoint rejects or fails to complete a query
+type SPARQLError struct {
+	Kind    SPARQLErrorKind
+	Message string
+	HTTP    *HTTPError
+}
+
+func (se *SPARQLError) Error() string {
+	switch se.Kind {
+	case SPARQLErrorTimeout:
+		return "sparql query timed out"
+	case SPARQLErrorSyntax:
+		return fmt.Sprintf("sparql query syntax error: %s", se.Message)
+	default:
+		if se.HTTP == nil {
+			if se.Message != "" {
+				return fmt.Sprintf("sparql query failed: %s", se.Message)
+			}
+			return "sparql query failed"
+		}
+		return fmt.Sprintf("sparql query failed: %s", se.HTTP.Status)
+	}
+}
+
+func (se *SPARQLError) Unwrap() error {
+	// avoid returning a nil *HTTPError as a non-nil error
+	if se.HTTP == nil {
+		return nil
+	}
+	return se.HTTP
+}
+
+var sparqlSyntaxErrorMessage = regexp.MustCompile(`MalformedQueryException: ([^\n]+)`)
+
+// newSPARQLError parses a blazegraph error page into a SPARQLError
+func newSPARQLError(resp *http.Response) *SPARQLError {
+	herr := newHTTPError(resp)
+	serr := &SPARQLError{
+		Kind: SPARQLErrorOther,
+		HTTP: herr,
+	}
+	if strings.Contains(herr.Body, "TimeoutException") {
+		serr.Kind = SPARQLErrorTimeout
+	} else if match := sparqlSyntaxErrorMessage.FindStringSubmatch(herr.Body); match != nil {
+		serr.Kind = SPARQLErrorSyntax
+		serr.Message = strings.TrimSpace(match[1])
+	}
+	return serr
+}
+
+// IsTemporary reports whether err is likely to go away if the request is retried later.
+// This includes throttling, server errors and maxlag, but not query timeouts or syntax errors.
+func IsTemporary(err error) bool {
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) {
+		return temporary.Temporary()
+	}
+	return false
+}
@@ -0,0 +1,377 @@
+package quickiedata
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Labeler looks up the label of an entity in a language
+type Labeler interface {
+	Label(ctx context.Context, id string, language string) (string, error)
+}
+
+// LabelMap is a Labeler backed by a map of entity id to label, ignoring the language
+type LabelMap map[string]string
+
+func (lm LabelMap) Label(ctx context.Context, id string, language string) (string, error) {
+	return lm[id], nil
+}
+
+type FormatOptions struct {
+	// Language is the language used for entity labels
+	Language string
+	// LabelResolver replaces entity ids with labels if set
+	LabelResolver Labeler
+	// ShowIDs keeps the entity id next to resolved labels, eg "Q5 (human)"
+	ShowIDs bool
+}
+
+func NewFormatOptions() *FormatOptions {
+	return &FormatOptions{
+		Language: "en",
+	}
+}
+
+var monthNames = []string{
+	"January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December",
+}
+
+// Format returns a human readable string for a snak value, such as the values of
+// SimpleClaim.Value and SimpleSnakValue.Value, or a claim or snak itself. Labels are only
+// looked up for entity values and claims or snaks with an entity type, plain strings are
+// returned as they are.
+func Format(value any, options *FormatOptions) string {
+	return FormatContext(context.Background(), value, options)
+}
+
+// FormatContext is Format with a context that is passed to the label resolver
+func FormatContext(ctx context.Context, value any, options *FormatOptions) string {
+	if options == nil {
+		options = NewFormatOptions()
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case SpecialSnakValue:
+		if v == NoValue {
+			return "no value"
+		}
+		return "unknown value"
+	case string:
+		return v
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	case *SnakValueEntity:
+		if v == nil {
+			return ""
+		}
+		return formatEntityID(ctx, v.GetID(), options)
+	case *SnakValueMonolingualText:
+		if v == nil {
+			return ""
+		}
+		if v.Text != "" {
+			return v.Text
+		}
+		return v.Value
+	case *SnakValueTime:
+		return FormatTime(v)
+	case *SnakValueQuantity:
+		return formatQuantity(ctx, v, options)
+	case *SnakValueGlobeCoordinate:
+		return formatCoordinate(ctx, v, options)
+	case *SnakValue:
+		if v == nil {
+			return ""
+		}
+		return FormatContext(ctx, v.Value, options)
+	case *SimpleSnakValue:
+		if v == nil {
+			return ""
+		}
+		return formatTypedValue(ctx, v.Type, v.Value, options)
+	case *SimpleClaim:
+		if v == nil {
+			return ""
+		}
+		return formatTypedValue(ctx, v.Type, v.Value, options)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatTypedValue formats the value of a claim or snak, only looking up a label if the type has an
+// entity as value. Strings and identifiers that look like entity ids are left as they are.
+func formatTypedValue(ctx context.Context, valueType string, value any, options *FormatOptions) string {
+	if id, ok := value.(*string); ok && id != nil && (SimpleTypeIsEntity(valueType) || DataTypeIsEntity(valueType)) {
+		return formatEntityID(ctx, *id, options)
+	}
+	return FormatContext(ctx, value, options)
+}
+
+// formatEntityID returns the label of a string that is an entity id, or the string unchanged
+func formatEntityID(ctx context.Context, id string, options *FormatOptions) string {
+	label := formatEntityLabel(ctx, id, options)
+	if options.ShowIDs && label != id {
+		return id + " (" + label + ")"
+	}
+	return label
+}
+
+// formatEntityLabel returns the label of an entity, or the id if there is no label
+func formatEntityLabel(ctx context.Context, id string, options *FormatOptions) string {
+	if options.LabelResolver == nil || !IsEntityID(id) {
+		return id
+	}
+	label, err := options.LabelResolver.Label(ctx, id, options.Language)
+	if err != nil {
+		DebugLog.Printf("failed to resolve label for %s: %s", id, err)
+		return id
+	}
+	if label == "" {
+		return id
+	}
+	return label
+}
+
+// FormatTime returns a time value rendered at its precision, for example
+// "19th century", "1990s", "March 1990" or "44 BCE"
+func FormatTime(s *SnakValueTime) string {
+	if s == nil {
+		return ""
+	}
+	parsed, err := ParseTime(s)
+	if err != nil {
+		return s.Time
+	}
+
+	era := ""
+	year := parsed.Year
+	if year < 0 {
+		era = " BCE"
+		year = -year
+	}
+
+	var output string
+	switch {
+	case parsed.Precision >= PrecisionDay:
+		output = fmt.Sprintf("%d %s %d%s", max(parsed.Day, 1), monthName(parsed.Month), year, era)
+		switch {
+		case parsed.Precision >= PrecisionSecond:
+			output += fmt.Sprintf(" %02d:%02d:%02d", parsed.Hour, parsed.Minute, parsed.Second)
+		case parsed.Precision >= PrecisionHour:
+			output += fmt.Sprintf(" %02d:%02d", parsed.Hour, parsed.Minute)
+		}
+		if parsed.Precision >= PrecisionHour && parsed.Timezone != 0 {
+			output += " " + formatTimezone(parsed.Timezone)
+		}
+	case parsed.Precision == PrecisionMonth:
+		output = fmt.Sprintf("%s %d%s", monthName(parsed.Month), year, era)
+	case parsed.Precision == PrecisionYear:
+		output = fmt.Sprintf("%d%s", year, era)
+	case parsed.Precision == PrecisionDecade:
+		output = fmt.Sprintf("%ds%s", year/10*10, era)
+	case parsed.Precision == PrecisionCentury:
+		output = fmt.Sprintf("%s century%s", ordinal((year-1)/100+1), era)
+	case parsed.Precision == PrecisionMillennium:
+		output = fmt.Sprintf("%s millennium%s", ordinal((year-1)/1000+1), era)
+	default:
+		output = formatYears(year) + era
+	}
+
+	if parsed.Calendar == CalendarJulian {
+		output += " (julian)"
+	}
+	return output
+}
+
+func monthName(month int) string {
+	if month < 1 || month > 12 {
+		return monthNames[0]
+	}
+	return monthNames[month-1]
+}
+
+func formatTimezone(minutes int) string {
+	sign := "+"
+	if minutes < 0 {
+		sign = "-"
+		minutes = -minutes
+	}
+	return fmt.Sprintf("UTC%s%02d:%02d", sign, minutes/60, minutes%60)
+}
+
+// ordinal returns the english ordinal of n, eg 1st, 2nd, 11th, 23rd
+func ordinal(n int64) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.FormatInt(n, 10) + suffix
+}
+
+// formatYears renders a large number of years, eg "13.798 billion years" or "10,000 years"
+func formatYears(years int64) string {
+	switch {
+	case years >= 1_000_000_000:
+		return strconv.FormatFloat(float64(years)/1e9, 'f', -1, 64) + " billion years"
+	case years >= 1_000_000:
+		return strconv.FormatFloat(float64(years)/1e6, 'f', -1, 64) + " million years"
+	default:
+		return groupThousands(strconv.FormatInt(years, 10)) + " years"
+	}
+}
+
+func groupThousands(digits string) string {
+	var output strings.Builder
+	for idx, digit := range digits {
+		if idx > 0 && (len(digits)-idx)%3 == 0 {
+			output.WriteByte(',')
+		}
+		output.WriteRune(digit)
+	}
+	return output.String()
+}
+
+// formatQuantity renders a quantity with its bounds and unit, eg "5 ± 0.5 km"
+func formatQuantity(ctx context.Context, q *SnakValueQuantity, options *FormatOptions) string {
+	if q == nil {
+		return ""
+	}
+	output := q.Amount.String()
+
+	above, below, err := q.Uncertainty()
+	if err == nil && above != "" {
+		if above == below {
+			if cmp, _ := above.Cmp("0"); cmp != 0 {
+				output += " ± " + above.String()
+			}
+		} else {
+			output += fmt.Sprintf(" (+%s, -%s)", above.String(), below.String())
+		}
+	}
+
+	unit := formatUnit(ctx, q.Unit, options)
+	switch unit {
+	case "":
+	case "%":
+		output += unit
+	default:
+		output += " " + unit
+	}
+	return output
+}
+
+func formatUnit(ctx context.Context, unit string, options *FormatOptions) string {
+	unit = normaliseUnit(unit)
+	if unit == "" {
+		return ""
+	}
+	if symbol, exists := LookupCommonUnits[unit]; exists {
+		return symbol
+	}
+	return formatEntityLabel(ctx, unit, options)
+}
+
+// formatCoordinate renders a coordinate in degrees, minutes and seconds, eg 51°30'26"N, 0°7'39"W.
+// The globe is included if it is not earth.
+func formatCoordinate(ctx context.Context, c *SnakValueGlobeCoordinate, options *FormatOptions) string {
+	if c == nil {
+		return ""
+	}
+	output := formatDMS(c.Latitude, c.Precision, "N", "S") + ", " + formatDMS(c.Longitude, c.Precision, "E", "W")
+
+	globe := GetWikidataIDFromURL(c.Globe)
+	if globe != "" && globe != "Q2" {
+		if name, exists := LookupCommonGlobes[globe]; exists {
+			output += " (" + name + ")"
+		} else {
+			output += " (" + formatEntityLabel(ctx, globe, options) + ")"
+		}
+	}
+	return output
+}
+
+// formatDMS renders an angle in degrees, minutes and seconds, only down to the given precision in degrees
+func formatDMS(angle float64, precision float64, positive string, negative string) string {
+	direction := positive
+	if angle < 0 {
+		direction = negative
+		angle = -angle
+	}
+
+	switch {
+	case precision >= 1:
+		return fmt.Sprintf("%.0f°%s", angle, direction)
+	case precision >= 1.0/60:
+		minutes := math.Round(angle * 60)
+		return fmt.Sprintf("%d°%d'%s", int64(minutes)/60, int64(minutes)%60, direction)
+	}
+
+	// show enough decimal places of seconds for the precision, allowing for
+	// precisions like 0.000277778 that are rounded from an arcsecond
+	decimals := 0
+	if precision > 0 && precision < 0.99/3600 {
+		decimals = int(math.Ceil(-math.Log10(precision*3600) - 1e-9))
+	}
+	scale := math.Pow(10, float64(decimals))
+	total := math.Round(angle*3600*scale) / scale
+	degrees := math.Floor(total / 3600)
+	minutes := math.Floor((total - degrees*3600) / 60)
+	seconds := total - degrees*3600 - minutes*60
+	return fmt.Sprintf("%.0f°%.0f'%s\"%s", degrees, minutes, strconv.FormatFloat(seconds, 'f', decimals, 64), direction)
+}
+
+// FormattedClaim is a claim with its value and qualifiers rendered by Format
+type FormattedClaim struct {
+	Value      string              `json:"value"`
+	Rank       string              `json:"rank,omitempty"`
+	Qualifiers map[string][]string `json:"qualifiers,omitempty"`
+}
+
+// FormatClaims renders simplified claims for display. The properties of the claims and
+// qualifiers are formatted as well, so with ShowIDs the keys look like "P31 (instance of)".
+func FormatClaims(ctx context.Context, claims map[string][]*SimpleClaim, options *FormatOptions) map[string][]*FormattedClaim {
+	if options == nil {
+		options = NewFormatOptions()
+	}
+	output := make(map[string][]*FormattedClaim, len(claims))
+	for property, propertyClaims := range claims {
+		var formatted []*FormattedClaim
+		for _, claim := range propertyClaims {
+			if claim == nil {
+				continue
+			}
+			item := &FormattedClaim{
+				Value: FormatContext(ctx, claim, options),
+				Rank:  claim.Rank,
+			}
+			for qualifierProperty, qualifiers := range claim.Qualifiers {
+				if item.Qualifiers == nil {
+					item.Qualifiers = make(map[string][]string)
+				}
+				key := formatEntityID(ctx, qualifierProperty, options)
+				for _, qualifier := range qualifiers {
+					item.Qualifiers[key] = append(item.Qualifiers[key], FormatContext(ctx, qualifier, options))
+				}
+			}
+			formatted = append(formatted, item)
+		}
+		output[formatEntityID(ctx, property, options)] = formatted
+	}
+	return output
+}
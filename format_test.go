@@ -0,0 +1,88 @@
+package quickiedata_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/rohfle/quickiedata"
+)
+
+func TestFormat(t *testing.T) {
+	str := func(s string) *string { return &s }
+	options := quickiedata.NewFormatOptions()
+	options.LabelResolver = quickiedata.LabelMap{"Q5": "human", "Q99": "metre per second"}
+
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{"entity", &quickiedata.SimpleClaim{Type: "item", Value: str("Q5")}, "human"},
+		{"unknown entity", &quickiedata.SimpleClaim{Type: "item", Value: str("Q6")}, "Q6"},
+		{"entity value", &quickiedata.SnakValueEntity{ID: "Q5"}, "human"},
+		{"plain string", str("Q5"), "Q5"},
+		{"external id", &quickiedata.SimpleClaim{Type: "external", Value: str("Q5")}, "Q5"},
+		{"monolingual text", &quickiedata.SimpleSnakValue{Type: "string", Value: str("Q5")}, "Q5"},
+		{"string", str("hello"), "hello"},
+		{"somevalue", quickiedata.SomeValue, "unknown value"},
+		{"novalue", quickiedata.NoValue, "no value"},
+		{"day", &quickiedata.SnakValueTime{Time: "+1990-03-15T00:00:00Z", Precision: 11}, "15 March 1990"},
+		{"month", &quickiedata.SnakValueTime{Time: "+1990-03-01T00:00:00Z", Precision: 10}, "March 1990"},
+		{"year bce", &quickiedata.SnakValueTime{Time: "-0044-01-01T00:00:00Z", Precision: 9}, "44 BCE"},
+		{"decade", &quickiedata.SnakValueTime{Time: "+1994-01-01T00:00:00Z", Precision: 8}, "1990s"},
+		{"century", &quickiedata.SnakValueTime{Time: "+1850-01-01T00:00:00Z", Precision: 7}, "19th century"},
+		{"millennium", &quickiedata.SnakValueTime{Time: "+2001-01-01T00:00:00Z", Precision: 6}, "3rd millennium"},
+		{"billion years", &quickiedata.SnakValueTime{Time: "-13798000000-01-01T00:00:00Z", Precision: 3}, "13.798 billion years BCE"},
+		{"julian", &quickiedata.SnakValueTime{Time: "+1582-10-04T00:00:00Z", Precision: 11, CalendarModel: "Q1985786"}, "4 October 1582 (julian)"},
+		{"quantity", &quickiedata.SnakValueQuantity{Amount: "5", UpperBound: "5.5", LowerBound: "4.5", Unit: "Q828224"}, "5 ± 0.5 km"},
+		{"quantity precision", &quickiedata.SnakValueQuantity{Amount: "1.80", UpperBound: "1.90", LowerBound: "1.70", Unit: "Q11573"}, "1.80 ± 0.10 m"},
+		{"quantity asymmetric", &quickiedata.SnakValueQuantity{Amount: "5", UpperBound: "6", LowerBound: "4.5"}, "5 (+1, -0.5)"},
+		{"quantity resolved unit", &quickiedata.SnakValueQuantity{Amount: "3", Unit: "Q99"}, "3 metre per second"},
+		{"coordinate", &quickiedata.SnakValueGlobeCoordinate{Latitude: 51.507222, Longitude: -0.1275, Precision: 0.0002777777}, "51°30'26\"N, 0°7'39\"W"},
+		{"coordinate minutes", &quickiedata.SnakValueGlobeCoordinate{Latitude: 18.65, Longitude: 226.2, Precision: 1.0 / 60, Globe: "Q111"}, "18°39'N, 226°12'E (mars)"},
+		{"snak", &quickiedata.SimpleSnakValue{Type: "wikibase-item", Value: str("Q5")}, "human"},
+	}
+
+	for _, test := range tests {
+		if result := quickiedata.Format(test.value, options); result != test.expected {
+			t.Errorf("%s: expected %q got %q", test.name, test.expected, result)
+		}
+	}
+}
+
+func TestFormatClaims(t *testing.T) {
+	str := func(s string) *string { return &s }
+	options := quickiedata.NewFormatOptions()
+	options.LabelResolver = quickiedata.LabelMap{"P31": "instance of", "Q5": "human", "P580": "start time", "Q99": "metre"}
+	options.ShowIDs = true
+
+	claims := map[string][]*quickiedata.SimpleClaim{
+		"P31": {{
+			Type:  "item",
+			Value: str("Q5"),
+			Qualifiers: map[string][]*quickiedata.SimpleSnakValue{
+				"P580": {{Type: "time", Value: &quickiedata.SnakValueTime{Time: "+1990-01-01T00:00:00Z", Precision: 9}}},
+			},
+		}},
+		"P2048": {{
+			Type:  "quantity",
+			Rank:  "preferred",
+			Value: &quickiedata.SnakValueQuantity{Amount: "2", Unit: "Q99"},
+		}},
+		"P528": {{Type: "external", Value: str("Q5")}},
+	}
+	expected := map[string][]*quickiedata.FormattedClaim{
+		"P31 (instance of)": {{
+			Value:      "Q5 (human)",
+			Qualifiers: map[string][]string{"P580 (start time)": {"1990"}},
+		}},
+		"P2048": {{Value: "2 metre", Rank: "preferred"}},
+		"P528":  {{Value: "Q5"}},
+	}
+
+	result := quickiedata.FormatClaims(context.Background(), claims, options)
+	if diff := deep.Equal(result, expected); diff != nil {
+		t.Error(diff)
+	}
+}
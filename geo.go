@@ -0,0 +1,200 @@
+package quickiedata
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// LookupGlobeRadius is the mean radius in metres of the globes in LookupCommonGlobes
+var LookupGlobeRadius = map[string]float64{
+	"Q2":   6371008.8, // earth
+	"Q111": 3389500,   // mars
+	"Q308": 2439700,   // mercury
+	"Q313": 6051800,   // venus
+	"Q405": 1737400,   // moon
+}
+
+// globeID returns the id of the globe of a coordinate, defaulting to earth
+func (c *SnakValueGlobeCoordinate) globeID() string {
+	globe := GetWikidataIDFromURL(c.Globe)
+	if globe == "" {
+		return "Q2"
+	}
+	return globe
+}
+
+// GlobeRadius returns the mean radius in metres of the globe of the coordinate
+func (c *SnakValueGlobeCoordinate) GlobeRadius() (float64, error) {
+	radius, exists := LookupGlobeRadius[c.globeID()]
+	if !exists {
+		return 0, fmt.Errorf("unknown globe '%s'", c.globeID())
+	}
+	return radius, nil
+}
+
+// DistanceTo returns the great-circle distance in metres to another coordinate on the same globe
+func (c *SnakValueGlobeCoordinate) DistanceTo(other *SnakValueGlobeCoordinate) (float64, error) {
+	if c.globeID() != other.globeID() {
+		return 0, fmt.Errorf("coordinates are on different globes '%s' and '%s'", c.globeID(), other.globeID())
+	}
+	radius, err := c.GlobeRadius()
+	if err != nil {
+		return 0, err
+	}
+
+	// haversine formula
+	lat1, lat2 := degreesToRadians(c.Latitude), degreesToRadians(other.Latitude)
+	deltaLat := lat2 - lat1
+	deltaLon := degreesToRadians(other.Longitude - c.Longitude)
+	a := math.Pow(math.Sin(deltaLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(deltaLon/2), 2)
+	return 2 * radius * math.Asin(math.Min(1, math.Sqrt(a))), nil
+}
+
+// BearingTo returns the initial bearing in degrees clockwise from north to another coordinate
+func (c *SnakValueGlobeCoordinate) BearingTo(other *SnakValueGlobeCoordinate) float64 {
+	lat1, lat2 := degreesToRadians(c.Latitude), degreesToRadians(other.Latitude)
+	deltaLon := degreesToRadians(other.Longitude - c.Longitude)
+	y := math.Sin(deltaLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(deltaLon)
+	bearing := math.Mod(radiansToDegrees(math.Atan2(y, x))+360, 360)
+	return bearing
+}
+
+// BoundingBox is an area between two latitudes and longitudes in degrees. Longitudes are between
+// -180 and 180, and if the box crosses the antimeridian MinLongitude is greater than MaxLongitude.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// Contains reports whether the coordinate is inside the bounding box
+func (bb *BoundingBox) Contains(c *SnakValueGlobeCoordinate) bool {
+	if c.Latitude < bb.MinLatitude || c.Latitude > bb.MaxLatitude {
+		return false
+	}
+	longitude := wrapLongitude(c.Longitude)
+	if bb.MinLongitude > bb.MaxLongitude {
+		return longitude >= bb.MinLongitude || longitude <= bb.MaxLongitude
+	}
+	return longitude >= bb.MinLongitude && longitude <= bb.MaxLongitude
+}
+
+// BoundingBox returns the area the coordinate could be in, based on its precision in degrees
+func (c *SnakValueGlobeCoordinate) BoundingBox() *BoundingBox {
+	half := c.Precision / 2
+	bb := &BoundingBox{
+		MinLatitude:  math.Max(c.Latitude-half, -90),
+		MinLongitude: -180,
+		MaxLatitude:  math.Min(c.Latitude+half, 90),
+		MaxLongitude: 180,
+	}
+	if c.Precision < 360 {
+		bb.MinLongitude = wrapLongitude(c.Longitude - half)
+		bb.MaxLongitude = wrapLongitude(c.Longitude + half)
+	}
+	return bb
+}
+
+// wrapLongitude returns the same longitude between -180 and 180
+func wrapLongitude(longitude float64) float64 {
+	if longitude >= -180 && longitude <= 180 {
+		return longitude
+	}
+	wrapped := math.Mod(longitude+180, 360)
+	if wrapped < 0 {
+		wrapped += 360
+	}
+	return wrapped - 180
+}
+
+// WKT returns the coordinate as a well-known text point, in the same form as the wikidata query service.
+// Coordinates that are not on earth are prefixed with the globe url.
+func (c *SnakValueGlobeCoordinate) WKT() string {
+	point := fmt.Sprintf("Point(%s %s)", formatCoordinateNumber(c.Longitude), formatCoordinateNumber(c.Latitude))
+	if globe := c.globeID(); globe != "Q2" {
+		return "<http://www.wikidata.org/entity/" + globe + "> " + point
+	}
+	return point
+}
+
+// GeoJSON returns the coordinate as a GeoJSON point, including the altitude if known
+func (c *SnakValueGlobeCoordinate) GeoJSON() *GeoJSONGeometry {
+	coordinates := []float64{c.Longitude, c.Latitude}
+	if c.Altitude != nil {
+		coordinates = append(coordinates, *c.Altitude)
+	}
+	return &GeoJSONGeometry{
+		Type:        "Point",
+		Coordinates: coordinates,
+	}
+}
+
+func formatCoordinateNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func degreesToRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func radiansToDegrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+// GetCoordinates returns the coordinate location (P625) values of the item
+func (s *SimpleItem) GetCoordinates() []*SnakValueGlobeCoordinate {
+	var output []*SnakValueGlobeCoordinate
+	for _, claim := range s.GetClaims("P625") {
+		if coordinate := claim.ValueAsCoordinate(); coordinate != nil {
+			output = append(output, coordinate)
+		}
+	}
+	return output
+}
+
+// ItemDistance is an item and its distance in metres from another item
+type ItemDistance struct {
+	Item     *SimpleItem
+	Distance float64
+}
+
+// NearestItems returns up to limit items from candidates ordered by the distance between
+// their coordinate locations (P625) and the coordinate location of s. Candidates without
+// a coordinate on the same globe are skipped. A limit of 0 or less returns all items.
+func (s *SimpleItem) NearestItems(candidates []*SimpleItem, limit int) []*ItemDistance {
+	origins := s.GetCoordinates()
+	if len(origins) == 0 {
+		return nil
+	}
+
+	var output []*ItemDistance
+	for _, candidate := range candidates {
+		if candidate == nil || candidate == s {
+			continue
+		}
+		// use the closest pair of coordinates if either item has several
+		best := math.Inf(1)
+		for _, origin := range origins {
+			for _, coordinate := range candidate.GetCoordinates() {
+				if distance, err := origin.DistanceTo(coordinate); err == nil && distance < best {
+					best = distance
+				}
+			}
+		}
+		if !math.IsInf(best, 1) {
+			output = append(output, &ItemDistance{Item: candidate, Distance: best})
+		}
+	}
+
+	sort.SliceStable(output, func(i, j int) bool {
+		return output[i].Distance < output[j].Distance
+	})
+	if limit > 0 && len(output) > limit {
+		output = output[:limit]
+	}
+	return output
+}
@@ -0,0 +1,140 @@
+package quickiedata_test
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/rohfle/quickiedata"
+)
+
+func TestSnakValueGlobeCoordinateDistanceAndBearing(t *testing.T) {
+	london := &quickiedata.SnakValueGlobeCoordinate{Latitude: 51.5074, Longitude: -0.1278, Globe: "Q2"}
+	paris := &quickiedata.SnakValueGlobeCoordinate{Latitude: 48.8566, Longitude: 2.3522, Globe: "http://www.wikidata.org/entity/Q2"}
+
+	distance, err := london.DistanceTo(paris)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(distance-343_560) > 500 {
+		t.Errorf("unexpected distance %f", distance)
+	}
+	if bearing := london.BearingTo(paris); math.Abs(bearing-148.1) > 0.5 {
+		t.Errorf("unexpected bearing %f", bearing)
+	}
+
+	// the same angle on mars is a shorter distance
+	marsA := &quickiedata.SnakValueGlobeCoordinate{Latitude: 0, Longitude: 0, Globe: "Q111"}
+	marsB := &quickiedata.SnakValueGlobeCoordinate{Latitude: 0, Longitude: 1, Globe: "Q111"}
+	if distance, err := marsA.DistanceTo(marsB); err != nil || math.Abs(distance-59_158) > 10 {
+		t.Errorf("unexpected distance on mars %f %v", distance, err)
+	}
+	if _, err := london.DistanceTo(marsA); err == nil {
+		t.Error("expected error for coordinates on different globes")
+	}
+}
+
+func TestSnakValueGlobeCoordinateRendering(t *testing.T) {
+	altitude := 100.0
+	coordinate := &quickiedata.SnakValueGlobeCoordinate{Latitude: 52.5, Longitude: 8.25, Precision: 0.5, Altitude: &altitude}
+
+	box := coordinate.BoundingBox()
+	expectedBox := quickiedata.BoundingBox{MinLatitude: 52.25, MinLongitude: 8, MaxLatitude: 52.75, MaxLongitude: 8.5}
+	if *box != expectedBox {
+		t.Errorf("unexpected bounding box %+v", box)
+	}
+	if !box.Contains(coordinate) {
+		t.Error("expected bounding box to contain coordinate")
+	}
+
+	antimeridian := &quickiedata.SnakValueGlobeCoordinate{Latitude: -17, Longitude: 179.75, Precision: 1}
+	box = antimeridian.BoundingBox()
+	expectedBox = quickiedata.BoundingBox{MinLatitude: -17.5, MinLongitude: 179.25, MaxLatitude: -16.5, MaxLongitude: -179.75}
+	if *box != expectedBox {
+		t.Errorf("unexpected antimeridian bounding box %+v", box)
+	}
+	for _, longitude := range []float64{179.5, -179.9, 180.1} {
+		if !box.Contains(&quickiedata.SnakValueGlobeCoordinate{Latitude: -17, Longitude: longitude}) {
+			t.Errorf("expected antimeridian bounding box to contain longitude %v", longitude)
+		}
+	}
+	if box.Contains(&quickiedata.SnakValueGlobeCoordinate{Latitude: -17, Longitude: 0}) {
+		t.Error("expected antimeridian bounding box not to contain longitude 0")
+	}
+
+	if wkt := coordinate.WKT(); wkt != "Point(8.25 52.5)" {
+		t.Errorf("unexpected wkt %s", wkt)
+	}
+	mars := &quickiedata.SnakValueGlobeCoordinate{Latitude: 1, Longitude: 2, Globe: "Q111"}
+	if wkt := mars.WKT(); wkt != "<http://www.wikidata.org/entity/Q111> Point(2 1)" {
+		t.Errorf("unexpected wkt %s", wkt)
+	}
+
+	data, err := json.Marshal(coordinate.GeoJSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"type":"Point","coordinates":[8.25,52.5,100]}` {
+		t.Errorf("unexpected geojson %s", data)
+	}
+}
+
+func TestSimpleItemNearestItems(t *testing.T) {
+	bielefeld := loadTestSimpleItem(t, "Q2112")
+	verla := loadTestSimpleItem(t, "Q217447")
+	neihu := loadTestSimpleItem(t, "Q271094")
+	noCoordinates := &quickiedata.SimpleItem{}
+
+	nearest := bielefeld.NearestItems([]*quickiedata.SimpleItem{neihu, bielefeld, noCoordinates, verla}, 0)
+	if len(nearest) != 2 || nearest[0].Item != verla || nearest[1].Item != neihu {
+		t.Fatalf("unexpected nearest items %+v", nearest)
+	}
+	if nearest[0].Distance > nearest[1].Distance {
+		t.Errorf("expected items ordered by distance")
+	}
+	if limited := bielefeld.NearestItems([]*quickiedata.SimpleItem{neihu, verla}, 1); len(limited) != 1 || limited[0].Item != verla {
+		t.Errorf("unexpected limited nearest items %+v", limited)
+	}
+}
+
+func TestRenderSPARQLQueryCoordinate(t *testing.T) {
+	query := quickiedata.NewSPARQLQuery()
+	query.Template = `SELECT ?place WHERE { SERVICE wikibase:around { ?place wdt:P625 ?location . bd:serviceParam wikibase:center ?center ; wikibase:radius ?radius . } }`
+	query.Variables["center"] = &quickiedata.SnakValueGlobeCoordinate{Latitude: 52.5, Longitude: 8.25}
+	query.Variables["radius"] = 1.5
+
+	rendered, err := quickiedata.RenderSPARQLQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `SELECT ?place WHERE { BIND( "Point(8.25 52.5)"^^geo:wktLiteral as ?center) BIND( "1.5"^^xsd:double as ?radius)  SERVICE wikibase:around { ?place wdt:P625 ?location . bd:serviceParam wikibase:center ?center ; wikibase:radius ?radius . } }`
+	if rendered != expected {
+		t.Errorf("unexpected query\n%s", rendered)
+	}
+
+	for _, radius := range []any{math.NaN(), math.Inf(1), float32(math.Inf(-1))} {
+		query.Variables["radius"] = radius
+		if _, err := quickiedata.RenderSPARQLQuery(query); err == nil {
+			t.Errorf("expected error for radius %v", radius)
+		}
+	}
+	query.Variables["radius"] = float32(0.1)
+	rendered, err = quickiedata.RenderSPARQLQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rendered, `BIND( "0.1"^^xsd:double as ?radius)`) {
+		t.Errorf("unexpected float32 radius\n%s", rendered)
+	}
+	query.Variables["radius"] = 1e21
+	if rendered, err = quickiedata.RenderSPARQLQuery(query); err != nil || !strings.Contains(rendered, `BIND( "1e+21"^^xsd:double as ?radius)`) {
+		t.Errorf("unexpected large radius %v\n%s", err, rendered)
+	}
+
+	var missing *quickiedata.SnakValueGlobeCoordinate
+	query.Variables["center"] = missing
+	if _, err := quickiedata.RenderSPARQLQuery(query); err == nil {
+		t.Error("expected error for nil coordinate")
+	}
+}
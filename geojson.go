@@ -0,0 +1,212 @@
+package quickiedata
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// GeoJSONGeometry is a GeoJSON geometry object
+type GeoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// GeoJSONFeatureCollection is a GeoJSON FeatureCollection object
+type GeoJSONFeatureCollection struct {
+	Type     string            `json:"type"`
+	Features []*GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature is a GeoJSON Feature object
+type GeoJSONFeature struct {
+	Type       string           `json:"type"`
+	ID         string           `json:"id,omitempty"`
+	Geometry   *GeoJSONGeometry `json:"geometry"`
+	Properties map[string]any   `json:"properties"`
+}
+
+func newGeoJSONFeatureCollection() *GeoJSONFeatureCollection {
+	return &GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []*GeoJSONFeature{},
+	}
+}
+
+type GeoJSONOptions struct {
+	// Languages of the labels and descriptions added as properties, all languages if empty
+	Languages []string
+	// Claims are the properties whose values are added as feature properties, eg P31
+	Claims []string
+	// FormatOptions is used to render claim values, see Format
+	FormatOptions *FormatOptions
+	// GeometryVar is the sparql variable holding the point, the first variable with a point if empty
+	GeometryVar string
+}
+
+func NewGeoJSONOptions() *GeoJSONOptions {
+	return &GeoJSONOptions{
+		Languages:     []string{"en"},
+		FormatOptions: NewFormatOptions(),
+	}
+}
+
+// ToGeoJSON creates a feature for every coordinate location (P625) of the items in the response.
+// Coordinates that are not on earth are skipped. Features have the item id, labels, descriptions
+// and the claims in options as properties. A single language is added as "label" and "description",
+// otherwise labels and descriptions are maps by language.
+func (resp *GetEntitiesSimpleResponse) ToGeoJSON(options *GeoJSONOptions) *GeoJSONFeatureCollection {
+	if options == nil {
+		options = NewGeoJSONOptions()
+	}
+	collection := newGeoJSONFeatureCollection()
+
+	// sort the ids to keep output deterministic
+	var keys []string
+	for key := range resp.Entities {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		item := resp.GetEntityAsItem(key)
+		if item == nil {
+			continue
+		}
+		for _, coordinate := range item.GetCoordinates() {
+			if coordinate.globeID() != "Q2" {
+				continue
+			}
+			collection.Features = append(collection.Features, &GeoJSONFeature{
+				Type:       "Feature",
+				ID:         key,
+				Geometry:   coordinate.GeoJSON(),
+				Properties: itemGeoJSONProperties(key, item, options),
+			})
+		}
+	}
+	return collection
+}
+
+func itemGeoJSONProperties(id string, item *SimpleItem, options *GeoJSONOptions) map[string]any {
+	properties := map[string]any{
+		"id": id,
+	}
+	addTerms := func(name string, terms map[string]string) {
+		if len(options.Languages) == 1 {
+			if value, exists := terms[options.Languages[0]]; exists {
+				properties[name] = value
+			}
+			return
+		}
+		selected := make(map[string]string)
+		for lang, value := range terms {
+			if len(options.Languages) == 0 || ValueInSlice(lang, options.Languages) {
+				selected[lang] = value
+			}
+		}
+		if len(selected) > 0 {
+			properties[name+"s"] = selected
+		}
+	}
+	addTerms("label", item.Labels)
+	addTerms("description", item.Descriptions)
+
+	for _, property := range options.Claims {
+		var values []string
+		for _, claim := range item.GetClaims(property) {
+			values = append(values, Format(claim, options.FormatOptions))
+		}
+		if len(values) > 0 {
+			properties[property] = values
+		}
+	}
+	return properties
+}
+
+// ToGeoJSON creates a feature for every row with a well-known text point, such as the values
+// of wdt:P625. The other values of the row are added as properties. Rows without a point are skipped.
+func (resp *SPARQLSimpleResponse) ToGeoJSON(options *GeoJSONOptions) *GeoJSONFeatureCollection {
+	if options == nil {
+		options = NewGeoJSONOptions()
+	}
+	collection := newGeoJSONFeatureCollection()
+
+	for _, row := range resp.Results {
+		geometryVar := options.GeometryVar
+		if geometryVar == "" {
+			geometryVar = firstCoordinateVar(row)
+		}
+		coordinate := row[geometryVar].ValueAsCoordinate()
+		if coordinate == nil || coordinate.globeID() != "Q2" {
+			continue
+		}
+
+		properties := make(map[string]any)
+		for key, value := range row {
+			if key != geometryVar && value != nil {
+				properties[key] = value.Value
+			}
+		}
+		collection.Features = append(collection.Features, &GeoJSONFeature{
+			Type:       "Feature",
+			Geometry:   coordinate.GeoJSON(),
+			Properties: properties,
+		})
+	}
+	return collection
+}
+
+// firstCoordinateVar returns the first variable in alphabetical order holding a point
+func firstCoordinateVar(row map[string]*SimpleBindingValue) string {
+	var keys []string
+	for key := range row {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if row[key].ValueAsCoordinate() != nil {
+			return key
+		}
+	}
+	return ""
+}
+
+// ValueAsCoordinate parses a well-known text point such as "Point(8.5 52.0)", or returns nil
+func (s *SimpleBindingValue) ValueAsCoordinate() *SnakValueGlobeCoordinate {
+	coordinate, err := ParseWKTPoint(s.ValueAsString())
+	if err != nil {
+		return nil
+	}
+	return coordinate
+}
+
+var wktPointPattern = regexp.MustCompile(`^\s*(?:<([^>]*)>\s*)?(?i:point)\s*\(\s*(\S+)\s+(\S+)\s*\)\s*$`)
+
+// ParseWKTPoint parses a well-known text point as returned by the wikidata query service,
+// including the globe prefix used for coordinates that are not on earth
+func ParseWKTPoint(s string) (*SnakValueGlobeCoordinate, error) {
+	match := wktPointPattern.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("invalid wkt point '%s'", s)
+	}
+	longitude, err := strconv.ParseFloat(match[2], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wkt point '%s': %w", s, err)
+	}
+	latitude, err := strconv.ParseFloat(match[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wkt point '%s': %w", s, err)
+	}
+
+	globe := GetWikidataIDFromURL(match[1])
+	if globe == "" {
+		globe = "Q2"
+	}
+	return &SnakValueGlobeCoordinate{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Globe:     globe,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package quickiedata_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/rohfle/quickiedata"
+)
+
+func TestGetEntitiesSimpleResponseToGeoJSON(t *testing.T) {
+	resp := &quickiedata.GetEntitiesSimpleResponse{
+		Entities: map[string]any{
+			"Q2112":   loadTestSimpleItem(t, "Q2112"),
+			"Q217447": loadTestSimpleItem(t, "Q217447"),
+			"P31":     &quickiedata.SimpleProperty{},
+		},
+	}
+	options := quickiedata.NewGeoJSONOptions()
+	options.Claims = []string{"P17"}
+	options.FormatOptions.LabelResolver = quickiedata.LabelMap{"Q183": "Germany"}
+
+	collection := resp.ToGeoJSON(options)
+	if len(collection.Features) != 2 {
+		t.Fatalf("expected 2 features, got %d", len(collection.Features))
+	}
+	feature := collection.Features[0]
+	if feature.ID != "Q2112" || feature.Properties["label"] != "Bielefeld" {
+		t.Errorf("unexpected feature %+v", feature)
+	}
+	if diff := deep.Equal(feature.Properties["P17"], []string{"Germany"}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(feature.Geometry.Coordinates, []float64{8.5166666666667, 52.016666666667}); diff != nil {
+		t.Error(diff)
+	}
+
+	data, err := json.Marshal(collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded["type"] != "FeatureCollection" {
+		t.Errorf("unexpected geojson %s", data)
+	}
+}
+
+func TestSPARQLSimpleResponseToGeoJSON(t *testing.T) {
+	resp := &quickiedata.SPARQLSimpleResponse{
+		Results: []map[string]*quickiedata.SimpleBindingValue{
+			{
+				"item":     {Value: "Q2112"},
+				"location": {Value: "Point(8.5166666666667 52.016666666667)"},
+			},
+			{
+				"item": {Value: "Q1"},
+			},
+			{
+				"item":     {Value: "Q3"},
+				"location": {Value: "<http://www.wikidata.org/entity/Q111> Point(2 1)"},
+			},
+		},
+	}
+
+	collection := resp.ToGeoJSON(nil)
+	if len(collection.Features) != 1 {
+		t.Fatalf("expected 1 feature, got %d", len(collection.Features))
+	}
+	expected := &quickiedata.GeoJSONFeature{
+		Type:       "Feature",
+		Geometry:   &quickiedata.GeoJSONGeometry{Type: "Point", Coordinates: []float64{8.5166666666667, 52.016666666667}},
+		Properties: map[string]any{"item": "Q2112"},
+	}
+	if diff := deep.Equal(collection.Features[0], expected); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestParseWKTPoint(t *testing.T) {
+	coordinate, err := quickiedata.ParseWKTPoint("<http://www.wikidata.org/entity/Q111> Point(-2.5 1.25)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &quickiedata.SnakValueGlobeCoordinate{Latitude: 1.25, Longitude: -2.5, Globe: "Q111"}
+	if diff := deep.Equal(coordinate, expected); diff != nil {
+		t.Error(diff)
+	}
+	if _, err := quickiedata.ParseWKTPoint("LINESTRING(0 0, 1 1)"); err == nil {
+		t.Error("expected error for non point wkt")
+	}
+}
@@ -0,0 +1,465 @@
+package quickiedata
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultTraverseDepth is the maximum number of steps followed by a traversal if not set
+const DefaultTraverseDepth = 20
+
+// ErrNoReverseNeighbours is returned when a traversal needs reverse lookups that the neighbour source does not support
+var ErrNoReverseNeighbours = errors.New("neighbour source does not support reverse lookups")
+
+// Edge is a claim with property on entity From that has entity To as its value
+type Edge struct {
+	From     string `json:"from"`
+	Property string `json:"property"`
+	To       string `json:"to"`
+}
+
+// NeighbourSource finds the entities linked to entities by claims
+type NeighbourSource interface {
+	// Neighbours returns the edges of the claims of each id with one of properties, or any property if empty, by id
+	Neighbours(ctx context.Context, ids []string, properties []string) (map[string][]*Edge, error)
+}
+
+// ReverseNeighbourSource is a NeighbourSource that can also find the claims pointing to entities
+type ReverseNeighbourSource interface {
+	NeighbourSource
+	// ReverseNeighbours returns the edges of the claims with one of properties, or any property if empty,
+	// that have an id as value, by id
+	ReverseNeighbours(ctx context.Context, ids []string, properties []string) (map[string][]*Edge, error)
+}
+
+// Traverser answers class membership questions, following instance of (P31) and subclass of (P279)
+type Traverser interface {
+	// IsA reports whether an entity is an instance or subclass of a class, directly or through subclasses
+	IsA(ctx context.Context, id string, classID string) (bool, error)
+	// Ancestors returns the classes an entity is an instance or subclass of, directly or through subclasses
+	Ancestors(ctx context.Context, id string) ([]string, error)
+	// Descendants returns the subclasses of a class, directly or through other subclasses
+	Descendants(ctx context.Context, classID string) ([]string, error)
+}
+
+type TraverseOptions struct {
+	// Properties are followed at every step, eg P279
+	Properties []string
+	// FirstProperties are followed instead of Properties at the first step if set, eg P31
+	FirstProperties []string
+	// Reverse follows claims pointing to the entities instead of the claims of the entities
+	Reverse bool
+	// MaxDepth is the maximum number of steps, DefaultTraverseDepth if 0
+	MaxDepth int
+}
+
+// NewTraverseOptions creates options for the classes of an entity, P31 or P279 then P279 transitively
+func NewTraverseOptions() *TraverseOptions {
+	return &TraverseOptions{
+		Properties:      []string{"P279"},
+		FirstProperties: []string{"P31", "P279"},
+		MaxDepth:        DefaultTraverseDepth,
+	}
+}
+
+// Graph traverses claims between entities using a NeighbourSource. Each step of a traversal is
+// requested as one batch, entities are visited once so cycles end, and edges are cached.
+type Graph struct {
+	Source NeighbourSource
+
+	mu    sync.Mutex
+	edges map[graphEdgeKey][]*Edge
+}
+
+// anyProperty is the cache key property of edges along any property
+const anyProperty = "*"
+
+type graphEdgeKey struct {
+	id       string
+	property string
+	reverse  bool
+}
+
+// NewGraph creates a Graph that finds edges using source
+func NewGraph(source NeighbourSource) *Graph {
+	return &Graph{
+		Source: source,
+		edges:  make(map[graphEdgeKey][]*Edge),
+	}
+}
+
+// Edges returns the edges of ids along properties, or any property if empty, or the edges pointing
+// to ids if reverse is set
+func (g *Graph) Edges(ctx context.Context, ids []string, properties []string, reverse bool) (map[string][]*Edge, error) {
+	var reverseSource ReverseNeighbourSource
+	if reverse {
+		var ok bool
+		if reverseSource, ok = g.Source.(ReverseNeighbourSource); !ok {
+			return nil, ErrNoReverseNeighbours
+		}
+	}
+
+	// edges along any property are cached separately
+	keys := properties
+	if len(keys) == 0 {
+		keys = []string{anyProperty}
+	}
+
+	g.mu.Lock()
+	var missing []string
+	for _, id := range ids {
+		for _, property := range keys {
+			if _, cached := g.edges[graphEdgeKey{id, property, reverse}]; !cached {
+				missing = append(missing, id)
+				break
+			}
+		}
+	}
+	g.mu.Unlock()
+
+	if len(missing) > 0 {
+		var fetched map[string][]*Edge
+		var err error
+		if reverse {
+			fetched, err = reverseSource.ReverseNeighbours(ctx, missing, properties)
+		} else {
+			fetched, err = g.Source.Neighbours(ctx, missing, properties)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		g.mu.Lock()
+		for _, id := range missing {
+			for _, property := range keys {
+				g.edges[graphEdgeKey{id, property, reverse}] = []*Edge{}
+			}
+			for _, edge := range fetched[id] {
+				key := graphEdgeKey{id, edge.Property, reverse}
+				if len(properties) == 0 {
+					key.property = anyProperty
+				}
+				g.edges[key] = append(g.edges[key], edge)
+			}
+		}
+		g.mu.Unlock()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	output := make(map[string][]*Edge, len(ids))
+	for _, id := range ids {
+		for _, property := range keys {
+			output[id] = append(output[id], g.edges[graphEdgeKey{id, property, reverse}]...)
+		}
+	}
+	return output, nil
+}
+
+// Traverse returns the entities reachable from ids in breadth first order, not including ids
+func (g *Graph) Traverse(ctx context.Context, ids []string, options *TraverseOptions) ([]string, error) {
+	var output []string
+	err := g.walk(ctx, ids, options, func(id string) bool {
+		output = append(output, id)
+		return true
+	})
+	return output, err
+}
+
+// walk calls visit once for every entity reachable from ids until visit returns false
+func (g *Graph) walk(ctx context.Context, ids []string, options *TraverseOptions, visit func(id string) bool) error {
+	if options == nil {
+		options = NewTraverseOptions()
+	}
+	maxDepth := options.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultTraverseDepth
+	}
+
+	visited := make(map[string]bool)
+	for _, id := range ids {
+		visited[id] = true
+	}
+	frontier := ids
+	for depth := 0; depth < maxDepth && len(frontier) > 0; depth++ {
+		properties := options.Properties
+		if depth == 0 && len(options.FirstProperties) > 0 {
+			properties = options.FirstProperties
+		}
+		edges, err := g.Edges(ctx, frontier, properties, options.Reverse)
+		if err != nil {
+			return err
+		}
+
+		var next []string
+		for _, id := range frontier {
+			for _, edge := range edges[id] {
+				neighbour := edge.To
+				if options.Reverse {
+					neighbour = edge.From
+				}
+				if visited[neighbour] {
+					continue
+				}
+				visited[neighbour] = true
+				if !visit(neighbour) {
+					return nil
+				}
+				next = append(next, neighbour)
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// IsA reports whether an entity is an instance of (P31) a class or any of its subclasses (P279),
+// or a subclass of it. An entity is not a kind of itself.
+func (g *Graph) IsA(ctx context.Context, id string, classID string) (bool, error) {
+	found := false
+	err := g.walk(ctx, []string{id}, NewTraverseOptions(), func(ancestor string) bool {
+		found = ancestor == classID
+		return !found
+	})
+	return found, err
+}
+
+// Ancestors returns the classes an entity is an instance of (P31) or subclass of (P279), including
+// the classes those are subclasses of, nearest first
+func (g *Graph) Ancestors(ctx context.Context, id string) ([]string, error) {
+	return g.Traverse(ctx, []string{id}, NewTraverseOptions())
+}
+
+// Descendants returns the subclasses (P279) of a class and their subclasses, nearest first.
+// The source must be a ReverseNeighbourSource such as SPARQLNeighbours, otherwise
+// ErrNoReverseNeighbours is returned. EntityNeighbours cannot find descendants as the api
+// has no lookup of the claims pointing to an entity.
+func (g *Graph) Descendants(ctx context.Context, classID string) ([]string, error) {
+	options := NewTraverseOptions()
+	options.FirstProperties = nil
+	options.Reverse = true
+	return g.Traverse(ctx, []string{classID}, options)
+}
+
+// EntityNeighbours is a NeighbourSource that gets the claims of entities with GetEntitiesBulk.
+// It only follows claims forward, use SPARQLNeighbours for reverse lookups.
+type EntityNeighbours struct {
+	Client *WikidataClient
+	// Options are used to get the entities, Props should include claims
+	Options *GetEntitiesOptions
+	// SimplifyOptions are used to select claims, truthy claims by default like wdt: in sparql
+	SimplifyOptions *SimplifyOptions
+}
+
+// NewEntityNeighbours creates an EntityNeighbours that gets entities with client
+func NewEntityNeighbours(client *WikidataClient) *EntityNeighbours {
+	options := NewGetEntitiesOptions()
+	options.Props = []string{"claims"}
+	simplifyOptions := NewSimplifyOptions()
+	simplifyOptions.TruthyOnly = true
+	return &EntityNeighbours{
+		Client:          client,
+		Options:         options,
+		SimplifyOptions: simplifyOptions,
+	}
+}
+
+func (n *EntityNeighbours) Neighbours(ctx context.Context, ids []string, properties []string) (map[string][]*Edge, error) {
+	result, err := n.Client.GetEntitiesBulk(ctx, ids, n.Options)
+	if err != nil {
+		return nil, err
+	}
+	simple := result.SimplifyWithOptions(n.SimplifyOptions)
+
+	output := make(map[string][]*Edge)
+	for _, id := range ids {
+		var claims map[string][]*SimpleClaim
+		if item := simple.GetEntityAsItem(id); item != nil {
+			claims = item.Claims
+		} else if property := simple.GetEntityAsProperty(id); property != nil {
+			claims = property.Claims
+		}
+		output[id] = claimEdges(id, claims, properties)
+	}
+	return output, nil
+}
+
+// claimEdges returns the edges of the claims with one of properties, or any property if empty,
+// that have an entity datatype. Strings and identifiers that look like entity ids are not edges.
+func claimEdges(id string, claims map[string][]*SimpleClaim, properties []string) []*Edge {
+	if len(properties) == 0 {
+		for property := range claims {
+			properties = append(properties, property)
+		}
+		sortEntityIDs(properties)
+	}
+	var edges []*Edge
+	for _, property := range properties {
+		for _, to := range relatedIDsFromClaims(claims, []string{property}, true) {
+			if IsEntityID(to) {
+				edges = append(edges, &Edge{From: id, Property: property, To: to})
+			}
+		}
+	}
+	return edges
+}
+
+// ItemNeighbours is a NeighbourSource over items that are already loaded, such as items decoded
+// from a dump or from files, for traversals without any requests. Items are keyed by id.
+type ItemNeighbours map[string]*SimpleItem
+
+func (n ItemNeighbours) Neighbours(ctx context.Context, ids []string, properties []string) (map[string][]*Edge, error) {
+	output := make(map[string][]*Edge)
+	for _, id := range ids {
+		if item := n[id]; item != nil {
+			output[id] = claimEdges(id, item.Claims, properties)
+		}
+	}
+	return output, nil
+}
+
+func (n ItemNeighbours) ReverseNeighbours(ctx context.Context, ids []string, properties []string) (map[string][]*Edge, error) {
+	targets := make(map[string]bool)
+	for _, id := range ids {
+		targets[id] = true
+	}
+
+	// sort the ids to keep output deterministic
+	var keys []string
+	for key := range n {
+		keys = append(keys, key)
+	}
+	sortEntityIDs(keys)
+
+	output := make(map[string][]*Edge)
+	for _, key := range keys {
+		for _, edge := range claimEdges(key, n[key].Claims, properties) {
+			if targets[edge.To] {
+				output[edge.To] = append(output[edge.To], edge)
+			}
+		}
+	}
+	return output, nil
+}
+
+// SPARQLNeighbours is a ReverseNeighbourSource that finds truthy claims (wdt:) with sparql queries
+type SPARQLNeighbours struct {
+	Client  *WikidataClient
+	Options *GetSPARQLQueryOptions
+}
+
+// NewSPARQLNeighbours creates a SPARQLNeighbours that queries with client
+func NewSPARQLNeighbours(client *WikidataClient) *SPARQLNeighbours {
+	return &SPARQLNeighbours{
+		Client:  client,
+		Options: NewSPARQLQueryOptions(),
+	}
+}
+
+func (n *SPARQLNeighbours) Neighbours(ctx context.Context, ids []string, properties []string) (map[string][]*Edge, error) {
+	return n.query(ctx, ids, properties, "from")
+}
+
+func (n *SPARQLNeighbours) ReverseNeighbours(ctx context.Context, ids []string, properties []string) (map[string][]*Edge, error) {
+	return n.query(ctx, ids, properties, "to")
+}
+
+func (n *SPARQLNeighbours) query(ctx context.Context, ids []string, properties []string, bound string) (map[string][]*Edge, error) {
+	query := NewSPARQLQuery()
+	query.Template = `SELECT ?from ?property ?to WHERE {
+		?from ?property ?to .
+		FILTER(isIRI(?to) && STRSTARTS(STR(?property), "http://www.wikidata.org/prop/direct/"))
+	}`
+	query.Variables[bound] = prefixedIDs("wd:", ids)
+	if len(properties) > 0 {
+		query.Variables["property"] = prefixedIDs("wdt:", properties)
+	}
+
+	result, err := n.Client.SPARQLQuerySimple(ctx, query, n.Options)
+	if err != nil {
+		return nil, err
+	}
+	output := make(map[string][]*Edge)
+	for _, row := range result.Results {
+		edge := &Edge{
+			From:     row["from"].ValueAsString(),
+			Property: row["property"].ValueAsString(),
+			To:       row["to"].ValueAsString(),
+		}
+		if !IsEntityID(edge.From) || !IsEntityID(edge.To) {
+			continue
+		}
+		if bound == "from" {
+			output[edge.From] = append(output[edge.From], edge)
+		} else {
+			output[edge.To] = append(output[edge.To], edge)
+		}
+	}
+	return output, nil
+}
+
+func prefixedIDs(prefix string, ids []string) []WikidataID {
+	var output []WikidataID
+	for _, id := range ids {
+		output = append(output, WikidataID(prefix+id))
+	}
+	return output
+}
+
+// SPARQLTraverser is a Traverser that answers with one sparql query using property paths,
+// such as wdt:P31/wdt:P279*, instead of fetching each step
+type SPARQLTraverser struct {
+	Client  *WikidataClient
+	Options *GetSPARQLQueryOptions
+}
+
+// NewSPARQLTraverser creates a SPARQLTraverser that queries with client
+func NewSPARQLTraverser(client *WikidataClient) *SPARQLTraverser {
+	return &SPARQLTraverser{
+		Client:  client,
+		Options: NewSPARQLQueryOptions(),
+	}
+}
+
+func (t *SPARQLTraverser) IsA(ctx context.Context, id string, classID string) (bool, error) {
+	query := NewSPARQLQuery()
+	query.Template = `SELECT ?item WHERE { ?item (wdt:P31|wdt:P279)/wdt:P279* ?class . } LIMIT 1`
+	query.Variables["item"] = WikidataID("wd:" + id)
+	query.Variables["class"] = WikidataID("wd:" + classID)
+	result, err := t.Client.SPARQLQuerySimple(ctx, query, t.Options)
+	if err != nil {
+		return false, err
+	}
+	return len(result.Results) > 0, nil
+}
+
+func (t *SPARQLTraverser) Ancestors(ctx context.Context, id string) ([]string, error) {
+	query := NewSPARQLQuery()
+	query.Template = `SELECT DISTINCT ?class WHERE { ?item (wdt:P31|wdt:P279)/wdt:P279* ?class . }`
+	query.Variables["item"] = WikidataID("wd:" + id)
+	return t.queryClasses(ctx, query)
+}
+
+func (t *SPARQLTraverser) Descendants(ctx context.Context, classID string) ([]string, error) {
+	query := NewSPARQLQuery()
+	query.Template = `SELECT DISTINCT ?class WHERE { ?class wdt:P279+ ?root . }`
+	query.Variables["root"] = WikidataID("wd:" + classID)
+	return t.queryClasses(ctx, query)
+}
+
+func (t *SPARQLTraverser) queryClasses(ctx context.Context, query *SPARQLQuery) ([]string, error) {
+	result, err := t.Client.SPARQLQuerySimple(ctx, query, t.Options)
+	if err != nil {
+		return nil, err
+	}
+	var output []string
+	for _, row := range result.Results {
+		if class := row["class"].ValueAsString(); IsEntityID(class) {
+			output = append(output, class)
+		}
+	}
+	// sparql results have no order, sort to keep output deterministic
+	sortEntityIDs(output)
+	return output, nil
+}
@@ -0,0 +1,285 @@
+package quickiedata_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/rohfle/quickiedata"
+)
+
+// newTestTaxonomy returns items where Q1 is an instance of Q10, and Q10 -> Q11 -> Q12 -> Q10
+// is a cycle of subclasses, with Q11 also a subclass of Q13. Q13 has a title and catalogue code
+// that look like entity ids.
+func newTestTaxonomy() quickiedata.ItemNeighbours {
+	str := func(s string) *string { return &s }
+	claim := func(ids ...string) []*quickiedata.SimpleClaim {
+		var claims []*quickiedata.SimpleClaim
+		for _, id := range ids {
+			claims = append(claims, &quickiedata.SimpleClaim{Type: "item", Value: str(id)})
+		}
+		return claims
+	}
+	return quickiedata.ItemNeighbours{
+		"Q1":  {Claims: map[string][]*quickiedata.SimpleClaim{"P31": claim("Q10")}},
+		"Q10": {Claims: map[string][]*quickiedata.SimpleClaim{"P279": claim("Q11")}},
+		"Q11": {Claims: map[string][]*quickiedata.SimpleClaim{"P279": claim("Q12", "Q13")}},
+		"Q12": {Claims: map[string][]*quickiedata.SimpleClaim{"P279": claim("Q10")}},
+		"Q13": {Claims: map[string][]*quickiedata.SimpleClaim{
+			"P1476": {{Type: "monolingualtext", Value: str("Q99")}},
+			"P528":  {{Type: "external", Value: str("Q1")}},
+		}},
+	}
+}
+
+func TestGraphOffline(t *testing.T) {
+	ctx := context.Background()
+	graph := quickiedata.NewGraph(newTestTaxonomy())
+
+	ancestors, err := graph.Ancestors(ctx, "Q1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(ancestors, []string{"Q10", "Q11", "Q12", "Q13"}); diff != nil {
+		t.Errorf("ancestors: %v", diff)
+	}
+
+	descendants, err := graph.Descendants(ctx, "Q11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(descendants, []string{"Q10", "Q12"}); diff != nil {
+		t.Errorf("descendants: %v", diff)
+	}
+
+	tests := []struct {
+		id       string
+		classID  string
+		expected bool
+	}{
+		{"Q1", "Q13", true},
+		{"Q10", "Q12", true},
+		{"Q1", "Q99", false},
+		{"Q13", "Q13", false},
+	}
+	for _, test := range tests {
+		result, err := graph.IsA(ctx, test.id, test.classID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != test.expected {
+			t.Errorf("IsA(%s, %s): expected %v", test.id, test.classID, test.expected)
+		}
+	}
+
+	// values that look like entity ids are only followed if the claim has an entity datatype
+	anyProperty := &quickiedata.TraverseOptions{}
+	reachable, err := graph.Traverse(ctx, []string{"Q13"}, anyProperty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reachable) != 0 {
+		t.Errorf("expected no entities reachable from Q13, got %v", reachable)
+	}
+	reachable, err = graph.Traverse(ctx, []string{"Q12"}, anyProperty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(reachable, []string{"Q10", "Q11", "Q13"}); diff != nil {
+		t.Errorf("any property: %v", diff)
+	}
+
+	options := quickiedata.NewTraverseOptions()
+	options.MaxDepth = 2
+	bounded, err := graph.Traverse(ctx, []string{"Q1"}, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(bounded, []string{"Q10", "Q11"}); diff != nil {
+		t.Errorf("bounded: %v", diff)
+	}
+}
+
+func TestGraphEntityNeighbours(t *testing.T) {
+	taxonomy := newTestTaxonomy()
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		entities := make(map[string]any)
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), "|") {
+			claims := make(map[string]any)
+			for property, values := range taxonomy[id].Claims {
+				var statements []any
+				for _, value := range values {
+					statements = append(statements, map[string]any{
+						"type": "statement",
+						"rank": "normal",
+						"mainsnak": map[string]any{
+							"snaktype": "value",
+							"property": property,
+							"datatype": "wikibase-item",
+							"datavalue": map[string]any{
+								"type":  "wikibase-entityid",
+								"value": map[string]any{"entity-type": "item", "id": *value.ValueAsString()},
+							},
+						},
+					})
+				}
+				claims[property] = statements
+			}
+			entities[id] = map[string]any{"id": id, "type": "item", "claims": claims}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": 1, "entities": entities})
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		APIEndpoint: server.URL,
+		Client:      server.Client(),
+	}
+	graph := quickiedata.NewGraph(quickiedata.NewEntityNeighbours(wd))
+
+	result, err := graph.IsA(context.Background(), "Q1", "Q13")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result {
+		t.Error("expected Q1 to be a Q13")
+	}
+	// one request per step: Q1, Q10, Q11
+	if requests.Load() != 3 {
+		t.Errorf("expected 3 requests, got %d", requests.Load())
+	}
+
+	// edges are cached, only Q12 and Q13 are new
+	if _, err := graph.Ancestors(context.Background(), "Q1"); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 4 {
+		t.Errorf("expected 4 requests, got %d", requests.Load())
+	}
+
+	if _, err := graph.Descendants(context.Background(), "Q11"); !errors.Is(err, quickiedata.ErrNoReverseNeighbours) {
+		t.Errorf("expected ErrNoReverseNeighbours, got %v", err)
+	}
+}
+
+func TestGraphEntityNeighboursDescendants(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		APIEndpoint: server.URL,
+		Client:      server.Client(),
+	}
+	graph := quickiedata.NewGraph(quickiedata.NewEntityNeighbours(wd))
+
+	// the api cannot find claims pointing to an entity, so nothing is requested
+	if _, err := graph.Descendants(context.Background(), "Q13"); !errors.Is(err, quickiedata.ErrNoReverseNeighbours) {
+		t.Errorf("expected ErrNoReverseNeighbours, got %v", err)
+	}
+	options := quickiedata.NewTraverseOptions()
+	options.Reverse = true
+	if _, err := graph.Traverse(context.Background(), []string{"Q13"}, options); !errors.Is(err, quickiedata.ErrNoReverseNeighbours) {
+		t.Errorf("expected ErrNoReverseNeighbours for reverse traversal, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("expected no requests, got %d", requests.Load())
+	}
+}
+
+func TestSPARQLTraverser(t *testing.T) {
+	var captured []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		captured = append(captured, string(body))
+		w.Header().Set("Content-Type", "application/sparql-results+json")
+		w.Write([]byte(`{
+			"head": {"vars": ["class"]},
+			"results": {"bindings": [
+				{"class": {"type": "uri", "value": "http://www.wikidata.org/entity/Q215627"}},
+				{"class": {"type": "uri", "value": "http://www.wikidata.org/entity/Q5"}}
+			]}
+		}`))
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		SPARQLEndpoint: server.URL,
+		Client:         server.Client(),
+	}
+	traverser := quickiedata.NewSPARQLTraverser(wd)
+
+	ancestors, err := traverser.Ancestors(context.Background(), "Q42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(ancestors, []string{"Q5", "Q215627"}); diff != nil {
+		t.Error(diff)
+	}
+
+	result, err := traverser.IsA(context.Background(), "Q42", "Q5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result {
+		t.Error("expected Q42 to be a Q5")
+	}
+
+	expected := []string{
+		"SELECT DISTINCT ?class WHERE { BIND( wd:Q42 as ?item)  ?item (wdt:P31|wdt:P279)/wdt:P279* ?class . }",
+		"SELECT ?item WHERE { BIND( wd:Q5 as ?class) BIND( wd:Q42 as ?item)  ?item (wdt:P31|wdt:P279)/wdt:P279* ?class . } LIMIT 1",
+	}
+	if diff := deep.Equal(captured, expected); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestSPARQLNeighbours(t *testing.T) {
+	var captured string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		captured = string(body)
+		w.Header().Set("Content-Type", "application/sparql-results+json")
+		w.Write([]byte(`{
+			"head": {"vars": ["from", "property", "to"]},
+			"results": {"bindings": [{
+				"from": {"type": "uri", "value": "http://www.wikidata.org/entity/Q5"},
+				"property": {"type": "uri", "value": "http://www.wikidata.org/prop/direct/P279"},
+				"to": {"type": "uri", "value": "http://www.wikidata.org/entity/Q215627"}
+			}]}
+		}`))
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		SPARQLEndpoint: server.URL,
+		Client:         server.Client(),
+	}
+	graph := quickiedata.NewGraph(quickiedata.NewSPARQLNeighbours(wd))
+
+	descendants, err := graph.Edges(context.Background(), []string{"Q215627"}, []string{"P279"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]*quickiedata.Edge{
+		"Q215627": {{From: "Q5", Property: "P279", To: "Q215627"}},
+	}
+	if diff := deep.Equal(descendants, expected); diff != nil {
+		t.Error(diff)
+	}
+	if !strings.Contains(captured, "VALUES ?property { wdt:P279 } VALUES ?to { wd:Q215627 }") {
+		t.Errorf("unexpected query %q", captured)
+	}
+}
@@ -58,6 +58,8 @@ func GetEntityIDFromNumericIDAndType(entityType string, numericID int64) (string
 	return fmt.Sprintf("%s%d", prefix, numericID), nil
 }
 
+// ConvertLanguage converts a locale such as en_US to its lowercase base language.
+// Use NormalizeLanguage to keep regional variants such as de-ch.
 func ConvertLanguage(language string) string {
 	return strings.ToLower(strings.SplitN(language, "_", 2)[0])
 }
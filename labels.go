@@ -0,0 +1,82 @@
+package quickiedata
+
+import (
+	"strings"
+)
+
+// ReferencedIDs returns the item and property ids used by the claims and qualifiers of simplified
+// entities, including the claim properties and quantity units, without duplicates
+func ReferencedIDs(entities ...any) []string {
+	var output []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if !seen[id] && (strings.HasPrefix(id, "Q") || strings.HasPrefix(id, "P")) && IsEntityID(id) {
+			seen[id] = true
+			output = append(output, id)
+		}
+	}
+	addValue := func(valueType string, value any) {
+		switch v := value.(type) {
+		case *string:
+			if v != nil && SimpleTypeIsEntity(valueType) {
+				add(*v)
+			}
+		case *SnakValueEntity:
+			if v != nil {
+				add(v.GetID())
+			}
+		case *SnakValueQuantity:
+			if v != nil {
+				add(GetWikidataIDFromURL(v.Unit))
+			}
+		}
+	}
+	addClaims := func(claims map[string][]*SimpleClaim) {
+		var properties []string
+		for property := range claims {
+			properties = append(properties, property)
+		}
+		// sort to keep output deterministic
+		sortEntityIDs(properties)
+		for _, property := range properties {
+			add(property)
+			for _, claim := range claims[property] {
+				addValue(claim.Type, claim.Value)
+				var qualifierProperties []string
+				for qualifierProperty := range claim.Qualifiers {
+					qualifierProperties = append(qualifierProperties, qualifierProperty)
+				}
+				sortEntityIDs(qualifierProperties)
+				for _, qualifierProperty := range qualifierProperties {
+					add(qualifierProperty)
+					for _, qualifier := range claim.Qualifiers[qualifierProperty] {
+						addValue(qualifier.Type, qualifier.Value)
+					}
+				}
+			}
+		}
+	}
+
+	for _, entity := range entities {
+		switch e := entity.(type) {
+		case *SimpleItem:
+			addClaims(e.Claims)
+		case *SimpleProperty:
+			addClaims(e.Claims)
+		case *SimpleLexeme:
+			add(e.LexicalCategory)
+			add(e.Language)
+			for _, form := range e.Forms {
+				addClaims(form.Claims)
+			}
+			for _, sense := range e.Senses {
+				addClaims(sense.Claims)
+			}
+		case *SimpleForm:
+			addClaims(e.Claims)
+		case *SimpleSense:
+			addClaims(e.Claims)
+		}
+	}
+	return output
+}
@@ -0,0 +1,36 @@
+package quickiedata_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/rohfle/quickiedata"
+)
+
+func TestReferencedIDs(t *testing.T) {
+	str := func(s string) *string { return &s }
+	item := &quickiedata.SimpleItem{
+		Claims: map[string][]*quickiedata.SimpleClaim{
+			"P31": {{Type: "item", Value: str("Q5")}},
+			"P1476": {{
+				Type:  "string",
+				Value: str("Q7"),
+				Qualifiers: map[string][]*quickiedata.SimpleSnakValue{
+					"P642": {{Type: "property", Value: str("P17")}},
+				},
+			}},
+			"P2048": {{Type: "quantity", Value: &quickiedata.SnakValueQuantity{Amount: "2", Unit: "http://www.wikidata.org/entity/Q11573"}}},
+		},
+	}
+	other := &quickiedata.SimpleItem{
+		Claims: map[string][]*quickiedata.SimpleClaim{
+			"P31": {{Type: "item", Value: str("Q5")}},
+		},
+	}
+
+	result := quickiedata.ReferencedIDs(item, other)
+	expected := []string{"P31", "Q5", "P1476", "P642", "P17", "P2048", "Q11573"}
+	if diff := deep.Equal(result, expected); diff != nil {
+		t.Error(diff)
+	}
+}
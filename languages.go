@@ -0,0 +1,232 @@
+package quickiedata
+
+import (
+	"strings"
+)
+
+// LookupLanguageAliases maps BCP 47 tags and old language codes to the codes wikidata uses
+var LookupLanguageAliases = map[string]string{
+	"en-us":      "en",
+	"iw":         "he",
+	"in":         "id",
+	"ji":         "yi",
+	"jw":         "jv",
+	"mo":         "ro",
+	"nb-no":      "nb",
+	"nn-no":      "nn",
+	"no-no":      "nb",
+	"pt-pt":      "pt",
+	"sr-cyrl":    "sr-ec",
+	"sr-latn":    "sr-el",
+	"zh-hans-cn": "zh-cn",
+	"zh-hans-sg": "zh-sg",
+	"zh-hans-my": "zh-my",
+	"zh-hant-tw": "zh-tw",
+	"zh-hant-hk": "zh-hk",
+	"zh-hant-mo": "zh-mo",
+	"zh-chs":     "zh-hans",
+	"zh-cht":     "zh-hant",
+}
+
+// LookupLanguageFallbacks are the languages tried after a language, based on the MediaWiki
+// fallback chains. Languages that are not listed fall back to their base language, eg de-at to de.
+// Every chain ends with mul and en.
+var LookupLanguageFallbacks = map[string][]string{
+	"af":          {"nl"},
+	"als":         {"gsw", "de"},
+	"an":          {"es"},
+	"ast":         {"es"},
+	"bar":         {"de"},
+	"be-tarask":   {"be"},
+	"br":          {"fr"},
+	"co":          {"it"},
+	"de-at":       {"de"},
+	"de-ch":       {"de"},
+	"de-formal":   {"de"},
+	"dsb":         {"hsb", "de"},
+	"en-ca":       {"en"},
+	"en-gb":       {"en"},
+	"es-formal":   {"es"},
+	"frc":         {"fr"},
+	"fy":          {"nl"},
+	"gl":          {"pt"},
+	"gsw":         {"de"},
+	"hsb":         {"dsb", "de"},
+	"jv":          {"id"},
+	"ksh":         {"de"},
+	"lb":          {"de"},
+	"li":          {"nl"},
+	"lij":         {"it"},
+	"nap":         {"it"},
+	"nb":          {"no", "nn"},
+	"nds":         {"nds-nl", "de"},
+	"nds-nl":      {"nds", "nl"},
+	"nl-informal": {"nl"},
+	"nn":          {"nb", "no"},
+	"no":          {"nb", "nn"},
+	"pdc":         {"de"},
+	"pt":          {"pt-br"},
+	"pt-br":       {"pt"},
+	"scn":         {"it"},
+	"sr-ec":       {"sr"},
+	"sr-el":       {"sr"},
+	"su":          {"id"},
+	"uk":          {"ru"},
+	"vec":         {"it"},
+	"vls":         {"nl"},
+	"wa":          {"fr"},
+	"yue":         {"zh-hk", "zh-hant", "zh"},
+	"zea":         {"nl"},
+	"zh":          {"zh-hans", "zh-hant", "zh-cn", "zh-tw", "zh-hk", "zh-sg", "zh-mo", "zh-my"},
+	"zh-cn":       {"zh-hans", "zh-sg", "zh-my", "zh"},
+	"zh-hans":     {"zh-cn", "zh-sg", "zh-my", "zh"},
+	"zh-hant":     {"zh-tw", "zh-hk", "zh-mo", "zh"},
+	"zh-hk":       {"zh-hant", "zh-mo", "zh-tw", "zh"},
+	"zh-mo":       {"zh-hk", "zh-hant", "zh-tw", "zh"},
+	"zh-my":       {"zh-sg", "zh-hans", "zh-cn", "zh"},
+	"zh-sg":       {"zh-hans", "zh-cn", "zh-my", "zh"},
+	"zh-tw":       {"zh-hant", "zh-hk", "zh-mo", "zh"},
+}
+
+// DefaultFallbackLanguages end every language fallback chain
+var DefaultFallbackLanguages = []string{"mul", "en"}
+
+// NormalizeLanguage converts a BCP 47 tag or locale, such as en_US.UTF-8, zh-Hant-HK or sr-Latn,
+// to the language code used by wikidata. Regions that only repeat the language are dropped,
+// so de-DE becomes de, while regional variants such as de-CH and en-GB are kept.
+func NormalizeLanguage(language string) string {
+	language = strings.TrimSpace(language)
+	// drop the encoding and modifier of posix locales
+	if idx := strings.IndexAny(language, ".@"); idx >= 0 {
+		language = language[:idx]
+	}
+	language = strings.ToLower(strings.ReplaceAll(language, "_", "-"))
+	if language == "" || language == "und" {
+		return ""
+	}
+	if alias, exists := LookupLanguageAliases[language]; exists {
+		return alias
+	}
+	if base, region, found := strings.Cut(language, "-"); found && base == region {
+		return base
+	}
+	return language
+}
+
+// LanguageFallbackChain returns the languages to try in order for a language, starting with the
+// language itself, eg de-ch gives de-ch, de, mul, en and zh-hk gives zh-hk, zh-hant, ..., zh, mul, en
+func LanguageFallbackChain(language string) []string {
+	return LanguageFallbackChains(language)
+}
+
+// LanguageFallbackChains returns the fallback chains of several languages joined in order,
+// without duplicates and with mul and en at the end
+func LanguageFallbackChains(languages ...string) []string {
+	var chain []string
+	add := func(language string) {
+		if language != "" && !ValueInSlice(language, chain) && !ValueInSlice(language, DefaultFallbackLanguages) {
+			chain = append(chain, language)
+		}
+	}
+	for _, language := range languages {
+		language = NormalizeLanguage(language)
+		add(language)
+		fallbacks, exists := LookupLanguageFallbacks[language]
+		if !exists {
+			if base, _, found := strings.Cut(language, "-"); found {
+				add(base)
+				fallbacks = LookupLanguageFallbacks[base]
+			}
+		}
+		for _, fallback := range fallbacks {
+			add(fallback)
+		}
+	}
+	return append(chain, DefaultFallbackLanguages...)
+}
+
+// getTermWithFallback returns the first term found following the fallback chains of languages
+func getTermWithFallback(terms map[string]string, languages []string) string {
+	for _, language := range LanguageFallbackChains(languages...) {
+		if value := terms[language]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// getAliasesWithFallback returns the first aliases found following the fallback chains of languages
+func getAliasesWithFallback(aliases map[string][]string, languages []string) []string {
+	for _, language := range LanguageFallbackChains(languages...) {
+		if values := aliases[language]; len(values) > 0 {
+			return values
+		}
+	}
+	return nil
+}
+
+// GetLabel returns the label in the first of languages that has one, following the
+// language fallback chains, eg de-ch falls back to de, mul and en
+func (s *SimpleItem) GetLabel(languages ...string) string {
+	if s == nil {
+		return ""
+	}
+	return getTermWithFallback(s.Labels, languages)
+}
+
+// GetDescription returns the description in the first of languages that has one, following the language fallback chains
+func (s *SimpleItem) GetDescription(languages ...string) string {
+	if s == nil {
+		return ""
+	}
+	return getTermWithFallback(s.Descriptions, languages)
+}
+
+// GetAliases returns the aliases in the first of languages that has any, following the language fallback chains
+func (s *SimpleItem) GetAliases(languages ...string) []string {
+	if s == nil {
+		return nil
+	}
+	return getAliasesWithFallback(s.Aliases, languages)
+}
+
+// GetLabel returns the label in the first of languages that has one, following the
+// language fallback chains, eg de-ch falls back to de, mul and en
+func (s *SimpleProperty) GetLabel(languages ...string) string {
+	if s == nil {
+		return ""
+	}
+	return getTermWithFallback(s.Labels, languages)
+}
+
+// GetDescription returns the description in the first of languages that has one, following the language fallback chains
+func (s *SimpleProperty) GetDescription(languages ...string) string {
+	if s == nil {
+		return ""
+	}
+	return getTermWithFallback(s.Descriptions, languages)
+}
+
+// GetAliases returns the aliases in the first of languages that has any, following the language fallback chains
+func (s *SimpleProperty) GetAliases(languages ...string) []string {
+	if s == nil {
+		return nil
+	}
+	return getAliasesWithFallback(s.Aliases, languages)
+}
+
+// GetRepresentation returns the representation in the first of languages that has one, following the language fallback chains
+func (s *SimpleForm) GetRepresentation(languages ...string) string {
+	if s == nil {
+		return ""
+	}
+	return getTermWithFallback(s.Representations, languages)
+}
+
+// GetGloss returns the gloss in the first of languages that has one, following the language fallback chains
+func (s *SimpleSense) GetGloss(languages ...string) string {
+	if s == nil {
+		return ""
+	}
+	return getTermWithFallback(s.Glosses, languages)
+}
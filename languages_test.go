@@ -0,0 +1,106 @@
+package quickiedata_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/rohfle/quickiedata"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := map[string]string{
+		"en":          "en",
+		"en_US.UTF-8": "en",
+		"de-CH":       "de-ch",
+		"de_DE":       "de",
+		"zh-Hant-HK":  "zh-hk",
+		"sr-Latn":     "sr-el",
+		"iw":          "he",
+		"pt-BR":       "pt-br",
+		"und":         "",
+	}
+	for input, expected := range tests {
+		if result := quickiedata.NormalizeLanguage(input); result != expected {
+			t.Errorf("%s: expected %q got %q", input, expected, result)
+		}
+	}
+}
+
+func TestLanguageFallbackChain(t *testing.T) {
+	tests := []struct {
+		languages []string
+		expected  []string
+	}{
+		{[]string{"de-ch"}, []string{"de-ch", "de", "mul", "en"}},
+		{[]string{"de-AT"}, []string{"de-at", "de", "mul", "en"}},
+		{[]string{"fr-be"}, []string{"fr-be", "fr", "mul", "en"}},
+		{[]string{"zh-hk"}, []string{"zh-hk", "zh-hant", "zh-mo", "zh-tw", "zh", "mul", "en"}},
+		{[]string{"en"}, []string{"mul", "en"}},
+		{[]string{"nds", "fr"}, []string{"nds", "nds-nl", "de", "fr", "mul", "en"}},
+		{nil, []string{"mul", "en"}},
+	}
+	for _, test := range tests {
+		result := quickiedata.LanguageFallbackChains(test.languages...)
+		if diff := deep.Equal(result, test.expected); diff != nil {
+			t.Errorf("%v: %v", test.languages, diff)
+		}
+	}
+}
+
+func TestGetTermsWithFallback(t *testing.T) {
+	item := &quickiedata.SimpleItem{
+		Labels:       map[string]string{"de": "Erde", "mul": "Terra", "en": "Earth"},
+		Descriptions: map[string]string{"en": "third planet from the Sun"},
+		Aliases:      map[string][]string{"de": {"Blauer Planet"}},
+	}
+	tests := []struct {
+		name     string
+		result   any
+		expected any
+	}{
+		{"label exact", item.GetLabel("de"), "Erde"},
+		{"label regional", item.GetLabel("de-ch"), "Erde"},
+		{"label mul", item.GetLabel("fr"), "Terra"},
+		{"label first language", item.GetLabel("fr", "de"), "Erde"},
+		{"description en", item.GetDescription("de-ch"), "third planet from the Sun"},
+		{"aliases", item.GetAliases("de-at"), []string{"Blauer Planet"}},
+		{"no aliases", item.GetAliases("fr"), []string(nil)},
+	}
+	for _, test := range tests {
+		if diff := deep.Equal(test.result, test.expected); diff != nil {
+			t.Errorf("%s: %v", test.name, diff)
+		}
+	}
+
+	var nilItem *quickiedata.SimpleItem
+	if label := nilItem.GetLabel("en"); label != "" {
+		t.Errorf("expected empty label for nil item, got %q", label)
+	}
+
+	lexeme := &quickiedata.SimpleLexeme{Lemmas: map[string]string{"en-gb": "colour", "en": "color"}}
+	if lemma := lexeme.GetLemma("en-gb"); lemma != "colour" {
+		t.Errorf("expected colour, got %q", lemma)
+	}
+	if lemma := lexeme.GetLemma("en-ca"); lemma != "color" {
+		t.Errorf("expected color, got %q", lemma)
+	}
+}
+
+func TestCreateGetEntitiesURLLanguageFallback(t *testing.T) {
+	wd := quickiedata.NewClient(nil)
+	options := quickiedata.NewGetEntitiesOptions()
+	options.Languages = []string{"de-ch"}
+	options.LanguageFallback = true
+	result, err := wd.CreateGetEntitiesURL([]string{"Q2"}, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Query().Get("languagefallback") != "1" || parsed.Query().Get("languages") != "de-ch" {
+		t.Errorf("unexpected url %s", result)
+	}
+}
@@ -0,0 +1,209 @@
+package quickiedata
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// bigFloatPrecision is the number of mantissa bits used by NumberPlus.BigFloat
+const bigFloatPrecision = 256
+
+// Rat parses the number exactly
+func (n NumberPlus) Rat() (*big.Rat, error) {
+	value, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		return nil, fmt.Errorf("invalid number '%s'", string(n))
+	}
+	return value, nil
+}
+
+// BigFloat parses the number with 256 bits of precision, use Rat for exact values
+func (n NumberPlus) BigFloat() (*big.Float, error) {
+	value, _, err := big.ParseFloat(string(n), 10, bigFloatPrecision, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number '%s': %w", string(n), err)
+	}
+	return value, nil
+}
+
+// Cmp returns -1, 0 or 1 depending on whether n is less than, equal to or greater than other
+func (n NumberPlus) Cmp(other NumberPlus) (int, error) {
+	a, b, err := ratPair(n, other)
+	if err != nil {
+		return 0, err
+	}
+	return a.Cmp(b), nil
+}
+
+// Add returns n + other, calculated exactly
+func (n NumberPlus) Add(other NumberPlus) (NumberPlus, error) {
+	return ratOperation(n, other, (*big.Rat).Add)
+}
+
+// Sub returns n - other, calculated exactly
+func (n NumberPlus) Sub(other NumberPlus) (NumberPlus, error) {
+	return ratOperation(n, other, (*big.Rat).Sub)
+}
+
+// Mul returns n * other, calculated exactly
+func (n NumberPlus) Mul(other NumberPlus) (NumberPlus, error) {
+	return ratOperation(n, other, (*big.Rat).Mul)
+}
+
+// Quo returns n / other, exact if the result has a terminating decimal, see formatRat
+func (n NumberPlus) Quo(other NumberPlus) (NumberPlus, error) {
+	a, b, err := ratPair(n, other)
+	if err != nil {
+		return "", err
+	}
+	if b.Sign() == 0 {
+		return "", fmt.Errorf("division by zero")
+	}
+	return NumberPlus(formatRat(a.Quo(a, b))), nil
+}
+
+func ratPair(a NumberPlus, b NumberPlus) (*big.Rat, *big.Rat, error) {
+	x, err := a.Rat()
+	if err != nil {
+		return nil, nil, err
+	}
+	y, err := b.Rat()
+	if err != nil {
+		return nil, nil, err
+	}
+	return x, y, nil
+}
+
+func ratOperation(a NumberPlus, b NumberPlus, op func(z, x, y *big.Rat) *big.Rat) (NumberPlus, error) {
+	x, y, err := ratPair(a, b)
+	if err != nil {
+		return "", err
+	}
+	return NumberPlus(formatRat(op(x, x, y))), nil
+}
+
+// formatRat returns an exact decimal string if the number has one, otherwise 20 significant digits
+func formatRat(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	// a fraction has a terminating decimal if its denominator only has factors of 2 and 5
+	denominator := new(big.Int).Set(r.Denom())
+	var twos, fives int
+	two, five := big.NewInt(2), big.NewInt(5)
+	remainder := new(big.Int)
+	for {
+		quotient, mod := new(big.Int).QuoRem(denominator, two, remainder)
+		if mod.Sign() != 0 {
+			break
+		}
+		denominator, twos = quotient, twos+1
+	}
+	for {
+		quotient, mod := new(big.Int).QuoRem(denominator, five, remainder)
+		if mod.Sign() != 0 {
+			break
+		}
+		denominator, fives = quotient, fives+1
+	}
+	if denominator.Cmp(big.NewInt(1)) == 0 {
+		return r.FloatString(max(twos, fives))
+	}
+
+	return new(big.Float).SetPrec(bigFloatPrecision).SetRat(r).Text('g', 20)
+}
+
+// Uncertainty returns how far the upper and lower bounds are from the amount,
+// or empty numbers if the quantity has no bounds
+func (q *SnakValueQuantity) Uncertainty() (NumberPlus, NumberPlus, error) {
+	if q.UpperBound == "" || q.LowerBound == "" {
+		return "", "", nil
+	}
+	above, err := q.UpperBound.Sub(q.Amount)
+	if err != nil {
+		return "", "", err
+	}
+	below, err := q.Amount.Sub(q.LowerBound)
+	if err != nil {
+		return "", "", err
+	}
+	// keep the precision of the values, so 1.80 and 1.90 differ by 0.10 rather than 0.1
+	above = padDecimals(above, max(decimalPlaces(q.Amount), decimalPlaces(q.UpperBound)))
+	below = padDecimals(below, max(decimalPlaces(q.Amount), decimalPlaces(q.LowerBound)))
+	return above, below, nil
+}
+
+// decimalPlaces returns the number of digits after the decimal point, or 0 for exponent notation
+func decimalPlaces(n NumberPlus) int {
+	s := string(n)
+	if strings.ContainsAny(s, "eE") {
+		return 0
+	}
+	if idx := strings.Index(s, "."); idx >= 0 {
+		return len(s) - idx - 1
+	}
+	return 0
+}
+
+// padDecimals adds trailing zeros to a decimal number with fewer than places digits after the point
+func padDecimals(n NumberPlus, places int) NumberPlus {
+	if places <= decimalPlaces(n) || strings.ContainsAny(string(n), "eE") {
+		return n
+	}
+	value, err := n.Rat()
+	if err != nil {
+		return n
+	}
+	return NumberPlus(value.FloatString(places))
+}
+
+// Compare returns -1, 0 or 1 depending on whether the amount of q is less than, equal to or greater
+// than the amount of other. Other is converted to the unit of q first if needed.
+func (q *SnakValueQuantity) Compare(other *SnakValueQuantity) (int, error) {
+	other, err := q.sameUnit(other)
+	if err != nil {
+		return 0, err
+	}
+	return q.Amount.Cmp(other.Amount)
+}
+
+// Overlaps reports whether the ranges between the bounds of q and other overlap, meaning the
+// quantities could be equal. Quantities without bounds only use their amount.
+func (q *SnakValueQuantity) Overlaps(other *SnakValueQuantity) (bool, error) {
+	other, err := q.sameUnit(other)
+	if err != nil {
+		return false, err
+	}
+	lowA, highA := q.bounds()
+	lowB, highB := other.bounds()
+	// ranges overlap if each starts before the other ends
+	cmp, err := lowA.Cmp(highB)
+	if err != nil || cmp > 0 {
+		return false, err
+	}
+	cmp, err = lowB.Cmp(highA)
+	if err != nil || cmp > 0 {
+		return false, err
+	}
+	return true, nil
+}
+
+func (q *SnakValueQuantity) bounds() (NumberPlus, NumberPlus) {
+	low, high := q.Amount, q.Amount
+	if q.LowerBound != "" {
+		low = q.LowerBound
+	}
+	if q.UpperBound != "" {
+		high = q.UpperBound
+	}
+	return low, high
+}
+
+func (q *SnakValueQuantity) sameUnit(other *SnakValueQuantity) (*SnakValueQuantity, error) {
+	if normaliseUnit(q.Unit) == normaliseUnit(other.Unit) {
+		return other, nil
+	}
+	return other.ConvertTo(q.Unit)
+}
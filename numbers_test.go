@@ -0,0 +1,122 @@
+package quickiedata_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/rohfle/quickiedata"
+)
+
+func TestNumberPlusPrecision(t *testing.T) {
+	n := quickiedata.NumberPlus("0.00000000000123")
+	r, err := n.Rat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.FloatString(14) != "0.00000000000123" {
+		t.Errorf("unexpected rat %s", r.FloatString(14))
+	}
+
+	population := quickiedata.NumberPlus("8000000000000000001")
+	f, err := population.BigFloat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Text('f', 0) != "8000000000000000001" {
+		t.Errorf("unexpected float %s", f.Text('f', 0))
+	}
+
+	if _, err := quickiedata.NumberPlus("abc").Rat(); err == nil {
+		t.Error("expected error for invalid number")
+	}
+}
+
+func TestNumberPlusArithmetic(t *testing.T) {
+	a := quickiedata.NumberPlus("0.1")
+	tests := []struct {
+		name     string
+		op       func(quickiedata.NumberPlus) (quickiedata.NumberPlus, error)
+		other    quickiedata.NumberPlus
+		expected quickiedata.NumberPlus
+	}{
+		{"add", a.Add, "0.2", "0.3"},
+		{"sub", a.Sub, "0.3", "-0.2"},
+		{"mul", a.Mul, "12345678901234567890", "1234567890123456789"},
+		{"quo", a.Quo, "8", "0.0125"},
+		{"quo repeating", a.Quo, "3", "0.033333333333333333333"},
+	}
+	for _, test := range tests {
+		result, err := test.op(test.other)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+		} else if result != test.expected {
+			t.Errorf("%s: expected %s got %s", test.name, test.expected, result)
+		}
+	}
+
+	if _, err := a.Quo("0"); err == nil {
+		t.Error("expected division by zero error")
+	}
+	if cmp, err := a.Cmp("0.10"); err != nil || cmp != 0 {
+		t.Errorf("expected 0.1 to equal 0.10, got %d %v", cmp, err)
+	}
+}
+
+func TestNumberPlusMarshalJSON(t *testing.T) {
+	raw := `{"amount":"+5","unit":"1","upperbound":"+5.5","lowerbound":"-4.5"}`
+	var quantity quickiedata.SnakValueQuantity
+	if err := json.Unmarshal([]byte(raw), &quantity); err != nil {
+		t.Fatal(err)
+	}
+	if quantity.Amount != "5" {
+		t.Errorf("expected prefix to be removed, got %s", quantity.Amount)
+	}
+	data, err := json.Marshal(&quantity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != raw {
+		t.Errorf("expected %s got %s", raw, data)
+	}
+
+	// the value of a raw quantity snak round trips unchanged
+	rawSnak := `{"snaktype":"value","property":"P2048","datavalue":{"value":{"amount":"+1.80","unit":"http://www.wikidata.org/entity/Q11573","upperbound":"+1.81","lowerbound":"+1.79"},"type":"quantity"},"datatype":"quantity"}`
+	var snak quickiedata.Snak
+	if err := json.Unmarshal([]byte(rawSnak), &snak); err != nil {
+		t.Fatal(err)
+	}
+	data, err = json.Marshal(&snak)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected, actual map[string]any
+	json.Unmarshal([]byte(rawSnak), &expected)
+	json.Unmarshal(data, &actual)
+	if diff := deep.Equal(actual["datavalue"], expected["datavalue"]); diff != nil {
+		t.Errorf("raw snak changed in round trip: %v\n%s", diff, data)
+	}
+}
+
+func TestSnakValueQuantityUncertaintyAndCompare(t *testing.T) {
+	height := &quickiedata.SnakValueQuantity{Amount: "1.80", UpperBound: "1.81", LowerBound: "1.75", Unit: "Q11573"}
+	above, below, err := height.Uncertainty()
+	if err != nil || above != "0.01" || below != "0.05" {
+		t.Errorf("unexpected uncertainty %s %s %v", above, below, err)
+	}
+	above, below, err = (&quickiedata.SnakValueQuantity{Amount: "1.80", UpperBound: "1.90", LowerBound: "1.5"}).Uncertainty()
+	if err != nil || above != "0.10" || below != "0.30" {
+		t.Errorf("expected uncertainty to keep precision, got %s %s %v", above, below, err)
+	}
+
+	feet := &quickiedata.SnakValueQuantity{Amount: "6", UpperBound: "6.5", LowerBound: "5.5", Unit: "Q3710"}
+	if cmp, err := height.Compare(feet); err != nil || cmp != -1 {
+		t.Errorf("expected 1.80 m to be less than 6 ft, got %d %v", cmp, err)
+	}
+	if overlaps, err := height.Overlaps(feet); err != nil || !overlaps {
+		t.Errorf("expected 1.80 m to overlap 6 ± 0.5 ft, got %t %v", overlaps, err)
+	}
+	if _, err := height.Compare(&quickiedata.SnakValueQuantity{Amount: "1", Unit: "Q11570"}); err == nil {
+		t.Error("expected error comparing length and mass")
+	}
+}
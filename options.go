@@ -1,20 +1,35 @@
 package quickiedata
 
+import "fmt"
+
+// MaxEntitiesPerRequest is the maximum number of ids wbgetentities accepts in one request
+const MaxEntitiesPerRequest = 50
+
 type GetEntitiesOptions struct {
 	Languages  []string
 	Sitefilter []string
 	Props      []string
 	Format     string
 	Redirects  bool
+	// LanguageFallback returns terms in a fallback language when Languages have none, eg de for de-ch
+	LanguageFallback bool
+	// ChunkSize is the number of ids sent per request by GetEntitiesBulk
+	ChunkSize int
+	// Concurrency is the number of chunk requests GetEntitiesBulk runs at once
+	Concurrency int
+	// MaxLag in seconds, requests fail with a MaxLagError if replication lag is higher
+	MaxLag int
 }
 
 func NewGetEntitiesOptions() *GetEntitiesOptions {
 	return &GetEntitiesOptions{
-		Languages:  []string{},
-		Sitefilter: []string{},
-		Props:      []string{},
-		Format:     "json",
-		Redirects:  true,
+		Languages:   []string{},
+		Sitefilter:  []string{},
+		Props:       []string{},
+		Format:      "json",
+		Redirects:   true,
+		ChunkSize:   MaxEntitiesPerRequest,
+		Concurrency: 4,
 	}
 }
 
@@ -25,6 +40,8 @@ type SearchEntitiesOptions struct {
 	Format     string
 	UseLang    string
 	EntityType string
+	// MaxLag in seconds, requests fail with a MaxLagError if replication lag is higher
+	MaxLag int
 }
 
 func NewSearchEntitiesOptions() *SearchEntitiesOptions {
@@ -38,13 +55,57 @@ func NewSearchEntitiesOptions() *SearchEntitiesOptions {
 	}
 }
 
+// SPARQLMethod is how a SPARQL query is sent to the endpoint
+type SPARQLMethod string
+
+const (
+	// SPARQLMethodGet sends the query in the url query string, which lets responses be cached
+	SPARQLMethodGet SPARQLMethod = "get"
+	// SPARQLMethodPostForm sends the query as a form encoded POST body
+	SPARQLMethodPostForm SPARQLMethod = "post-form"
+	// SPARQLMethodPostRaw sends the query as a raw application/sparql-query POST body
+	SPARQLMethodPostRaw SPARQLMethod = "post"
+)
+
 type GetSPARQLQueryOptions struct {
+	// Timeout in seconds, applied both as a client side deadline and as the
+	// server side timeout parameter. Values of 0 or -1 disable the timeout.
 	Timeout int64
+	Method  SPARQLMethod
 }
 
 func NewSPARQLQueryOptions() *GetSPARQLQueryOptions {
 	return &GetSPARQLQueryOptions{
 		Timeout: -1,
+		Method:  SPARQLMethodPostRaw,
+	}
+}
+
+// Validate checks the SPARQL query options are valid
+func (opt *GetSPARQLQueryOptions) Validate() error {
+	if opt.Timeout < -1 {
+		return fmt.Errorf("invalid sparql timeout %d", opt.Timeout)
+	}
+	switch opt.Method {
+	case "", SPARQLMethodGet, SPARQLMethodPostForm, SPARQLMethodPostRaw:
+		return nil
+	default:
+		return fmt.Errorf("invalid sparql method '%s'", opt.Method)
+	}
+}
+
+type SPARQLPagesOptions struct {
+	// PageSize is the number of rows requested per page using LIMIT and OFFSET
+	PageSize int64
+	// RequireOrderBy returns an error if the query has no ORDER BY clause,
+	// as without one the order of rows between pages is not guaranteed to be stable
+	RequireOrderBy bool
+}
+
+func NewSPARQLPagesOptions() *SPARQLPagesOptions {
+	return &SPARQLPagesOptions{
+		PageSize:       1000,
+		RequireOrderBy: false,
 	}
 }
 
@@ -0,0 +1,456 @@
+package quickiedata
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+)
+
+// OutputFormat is a format that records can be written in
+type OutputFormat string
+
+const (
+	OutputTable     OutputFormat = "table"
+	OutputCSV       OutputFormat = "csv"
+	OutputTSV       OutputFormat = "tsv"
+	OutputJSONLines OutputFormat = "jsonl"
+	OutputYAML      OutputFormat = "yaml"
+)
+
+var ListOfOutputFormats = []OutputFormat{
+	OutputTable,
+	OutputCSV,
+	OutputTSV,
+	OutputJSONLines,
+	OutputYAML,
+}
+
+// RecordWriter writes records that share the same columns. Table, CSV and TSV output render
+// values as text with Format, while JSON Lines and YAML keep the values as they are.
+type RecordWriter struct {
+	// FormatOptions is used to render values as text, see Format
+	FormatOptions *FormatOptions
+
+	format  OutputFormat
+	columns []string
+	w       io.Writer
+	csv     *csv.Writer
+	table   *tabwriter.Writer
+	encoder *json.Encoder
+}
+
+// NewRecordWriter creates a RecordWriter, writing the header for formats that have one
+func NewRecordWriter(w io.Writer, format OutputFormat, columns []string) (*RecordWriter, error) {
+	rw := &RecordWriter{
+		FormatOptions: NewFormatOptions(),
+		format:        format,
+		columns:       columns,
+		w:             w,
+	}
+
+	switch format {
+	case OutputCSV, OutputTSV:
+		rw.csv = csv.NewWriter(w)
+		if format == OutputTSV {
+			rw.csv.Comma = '\t'
+		}
+		if err := rw.csv.Write(columns); err != nil {
+			return nil, err
+		}
+	case OutputTable:
+		rw.table = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if err := rw.writeTableRow(columns); err != nil {
+			return nil, err
+		}
+		var underlines []string
+		for _, column := range columns {
+			underlines = append(underlines, strings.Repeat("-", len([]rune(column))))
+		}
+		if err := rw.writeTableRow(underlines); err != nil {
+			return nil, err
+		}
+	case OutputJSONLines:
+		rw.encoder = json.NewEncoder(w)
+		rw.encoder.SetEscapeHTML(false)
+	case OutputYAML:
+	default:
+		return nil, fmt.Errorf("unknown output format '%s'", format)
+	}
+	return rw, nil
+}
+
+// Write writes a record, with one value per column
+func (rw *RecordWriter) Write(values []any) error {
+	if len(values) != len(rw.columns) {
+		return fmt.Errorf("record has %d values but there are %d columns", len(values), len(rw.columns))
+	}
+
+	switch rw.format {
+	case OutputCSV, OutputTSV:
+		return rw.csv.Write(rw.textValues(values))
+	case OutputTable:
+		return rw.writeTableRow(rw.textValues(values))
+	case OutputJSONLines:
+		return rw.encoder.Encode(rw.orderedRecord(values))
+	case OutputYAML:
+		var buf bytes.Buffer
+		for idx, column := range rw.columns {
+			if idx == 0 {
+				buf.WriteString("- ")
+			} else {
+				buf.WriteString("  ")
+			}
+			if err := writeYAMLField(&buf, column, values[idx], 2); err != nil {
+				return err
+			}
+		}
+		_, err := rw.w.Write(buf.Bytes())
+		return err
+	}
+	return nil
+}
+
+// Close flushes buffered output. Tables are only aligned and written when closed.
+func (rw *RecordWriter) Close() error {
+	switch {
+	case rw.csv != nil:
+		rw.csv.Flush()
+		return rw.csv.Error()
+	case rw.table != nil:
+		return rw.table.Flush()
+	}
+	return nil
+}
+
+func (rw *RecordWriter) textValues(values []any) []string {
+	var output []string
+	for _, value := range values {
+		if sv, ok := value.(*SimpleBindingValue); ok {
+			value = sv.Value
+		}
+		output = append(output, Format(value, rw.FormatOptions))
+	}
+	return output
+}
+
+func (rw *RecordWriter) writeTableRow(cells []string) error {
+	// tabs and newlines in values would break the alignment
+	var escaped []string
+	for _, cell := range cells {
+		escaped = append(escaped, strings.NewReplacer("\t", " ", "\n", " ").Replace(cell))
+	}
+	_, err := io.WriteString(rw.table, strings.Join(escaped, "\t")+"\n")
+	return err
+}
+
+// orderedRecord is a json object that keeps the order of the columns
+type orderedRecord struct {
+	columns []string
+	values  []any
+}
+
+func (rw *RecordWriter) orderedRecord(values []any) *orderedRecord {
+	var unwrapped []any
+	for _, value := range values {
+		if sv, ok := value.(*SimpleBindingValue); ok {
+			value = sv.Value
+		}
+		unwrapped = append(unwrapped, value)
+	}
+	return &orderedRecord{columns: rw.columns, values: unwrapped}
+}
+
+func (or *orderedRecord) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for idx, column := range or.columns {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(column)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(or.values[idx])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeYAMLField writes "key: value" where value is converted to yaml through its json form
+func writeYAMLField(buf *bytes.Buffer, key string, value any, indent int) error {
+	if sv, ok := value.(*SimpleBindingValue); ok {
+		value = sv.Value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic any
+	if err := decoder.Decode(&generic); err != nil {
+		return err
+	}
+	buf.WriteString(yamlString(key) + ":")
+	writeYAMLValue(buf, generic, indent)
+	return nil
+}
+
+// writeYAMLValue writes a value decoded from json, starting after a key or list marker
+func writeYAMLValue(buf *bytes.Buffer, value any, indent int) {
+	prefix := strings.Repeat("  ", indent)
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		var keys []string
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteString("\n")
+		for _, key := range keys {
+			buf.WriteString(prefix + yamlString(key) + ":")
+			writeYAMLValue(buf, v[key], indent+1)
+		}
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteString("\n")
+		for _, item := range v {
+			buf.WriteString(prefix + "-")
+			writeYAMLValue(buf, item, indent+1)
+		}
+	case string:
+		buf.WriteString(" " + yamlString(v) + "\n")
+	case nil:
+		buf.WriteString(" null\n")
+	default:
+		// json.Number and bool are written as they are
+		buf.WriteString(fmt.Sprintf(" %v\n", v))
+	}
+}
+
+// yamlString returns a plain yaml scalar if it starts with a letter and only has letters, digits,
+// spaces and a few safe punctuation marks, otherwise a double quoted string. Anything else could be
+// read as a number, date, boolean or other yaml syntax by some parser.
+func yamlString(s string) string {
+	if s == "" || strings.TrimSpace(s) != s {
+		return quoteYAML(s)
+	}
+	for idx, r := range s {
+		if idx == 0 && !unicode.IsLetter(r) {
+			return quoteYAML(s)
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" _-./()", r) {
+			return quoteYAML(s)
+		}
+	}
+	switch strings.ToLower(s) {
+	case "y", "n", "yes", "no", "true", "false", "on", "off", "null":
+		return quoteYAML(s)
+	}
+	return s
+}
+
+func quoteYAML(s string) string {
+	// a json string is also a valid yaml double quoted string
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// WriteSPARQLResults writes sparql results with a column for each variable in resp.Vars.
+// If Vars is not set, the columns are every variable in the results in alphabetical order.
+func WriteSPARQLResults(w io.Writer, format OutputFormat, resp *SPARQLSimpleResponse) error {
+	columns := resp.Vars
+	if len(columns) == 0 {
+		seen := make(map[string]bool)
+		for _, row := range resp.Results {
+			for key := range row {
+				if !seen[key] {
+					seen[key] = true
+					columns = append(columns, key)
+				}
+			}
+		}
+		sort.Strings(columns)
+	}
+
+	rw, err := NewRecordWriter(w, format, columns)
+	if err != nil {
+		return err
+	}
+	for _, row := range resp.Results {
+		values := make([]any, len(columns))
+		for idx, column := range columns {
+			if value := row[column]; value != nil {
+				values[idx] = value.Value
+			}
+		}
+		if err := rw.Write(values); err != nil {
+			return err
+		}
+	}
+	return rw.Close()
+}
+
+// WriteSearchResults writes the id, label and description of search results
+func WriteSearchResults(w io.Writer, format OutputFormat, results []*SearchResult) error {
+	rw, err := NewRecordWriter(w, format, []string{"id", "label", "description"})
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if err := rw.Write([]any{result.ID, result.Label, result.Description}); err != nil {
+			return err
+		}
+	}
+	return rw.Close()
+}
+
+// WriteEntities writes entities as flattened rows of id, property and value. Labels, descriptions,
+// aliases, lemmas and sitelinks use properties such as "label:en" and "sitelink:enwiki". Claim values
+// are rendered with Format, so entity ids are replaced with labels if options has a LabelResolver.
+func WriteEntities(w io.Writer, format OutputFormat, resp *GetEntitiesSimpleResponse, options *FormatOptions) error {
+	if options == nil {
+		options = NewFormatOptions()
+	}
+	rw, err := NewRecordWriter(w, format, []string{"id", "property", "value"})
+	if err != nil {
+		return err
+	}
+	rw.FormatOptions = options
+
+	var keys []string
+	for key := range resp.Entities {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, row := range FlattenEntity(resp.Entities[key], options) {
+			if err := rw.Write([]any{key, row[0], row[1]}); err != nil {
+				return err
+			}
+		}
+	}
+	return rw.Close()
+}
+
+// FlattenEntity returns the property and value of every term, sitelink and claim of a
+// simplified entity, see WriteEntities
+func FlattenEntity(entity any, options *FormatOptions) [][2]string {
+	if options == nil {
+		options = NewFormatOptions()
+	}
+	ctx := context.Background()
+
+	var rows [][2]string
+	addTerms := func(name string, terms map[string]string) {
+		for _, key := range sortedKeys(terms) {
+			rows = append(rows, [2]string{name + ":" + key, terms[key]})
+		}
+	}
+	addAliases := func(aliases map[string][]string) {
+		for _, key := range sortedKeys(aliases) {
+			for _, alias := range aliases[key] {
+				rows = append(rows, [2]string{"alias:" + key, alias})
+			}
+		}
+	}
+	addClaims := func(claims map[string][]*SimpleClaim) {
+		var properties []string
+		for key := range claims {
+			properties = append(properties, key)
+		}
+		sortEntityIDs(properties)
+		for _, property := range properties {
+			name := formatEntityID(ctx, property, options)
+			for _, claim := range claims[property] {
+				rows = append(rows, [2]string{name, Format(claim, options)})
+			}
+		}
+	}
+
+	switch e := entity.(type) {
+	case *SimpleItem:
+		addTerms("label", e.Labels)
+		addTerms("description", e.Descriptions)
+		addAliases(e.Aliases)
+		addClaims(e.Claims)
+		addTerms("sitelink", e.Sitelinks)
+	case *SimpleProperty:
+		rows = append(rows, [2]string{"datatype", e.DataType})
+		addTerms("label", e.Labels)
+		addTerms("description", e.Descriptions)
+		addAliases(e.Aliases)
+		addClaims(e.Claims)
+	case *SimpleLexeme:
+		addTerms("lemma", e.Lemmas)
+		rows = append(rows, [2]string{"language", formatEntityID(ctx, e.Language, options)})
+		rows = append(rows, [2]string{"category", formatEntityID(ctx, e.LexicalCategory, options)})
+		for _, form := range e.Forms {
+			for _, key := range sortedKeys(form.Representations) {
+				rows = append(rows, [2]string{"form:" + form.ID, form.Representations[key]})
+			}
+		}
+		for _, sense := range e.Senses {
+			for _, key := range sortedKeys(sense.Glosses) {
+				rows = append(rows, [2]string{"sense:" + sense.ID, sense.Glosses[key]})
+			}
+		}
+	case *SimpleForm:
+		addTerms("representation", e.Representations)
+		addClaims(e.Claims)
+	case *SimpleSense:
+		addTerms("gloss", e.Glosses)
+		addClaims(e.Claims)
+	}
+	return rows
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortEntityIDs sorts ids by type and then by number, eg P17 before P131
+func sortEntityIDs(ids []string) {
+	sort.SliceStable(ids, func(i, j int) bool {
+		a, b := ids[i], ids[j]
+		if len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+			na, errA := strconv.ParseInt(a[1:], 10, 64)
+			nb, errB := strconv.ParseInt(b[1:], 10, 64)
+			if errA == nil && errB == nil {
+				return na < nb
+			}
+		}
+		return a < b
+	})
+}
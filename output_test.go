@@ -0,0 +1,149 @@
+package quickiedata_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rohfle/quickiedata"
+)
+
+func testSPARQLSimpleResponse() *quickiedata.SPARQLSimpleResponse {
+	return &quickiedata.SPARQLSimpleResponse{
+		Vars: []string{"item", "label", "count"},
+		Results: []map[string]*quickiedata.SimpleBindingValue{
+			{"item": {Value: "Q1"}, "label": {Value: "universe, the"}, "count": {Value: int64(3)}},
+			{"item": {Value: "Q42"}, "count": {Value: int64(12)}},
+		},
+	}
+}
+
+func TestWriteSPARQLResults(t *testing.T) {
+	tests := []struct {
+		format   quickiedata.OutputFormat
+		expected string
+	}{
+		{quickiedata.OutputCSV, "item,label,count\nQ1,\"universe, the\",3\nQ42,,12\n"},
+		{quickiedata.OutputTSV, "item\tlabel\tcount\nQ1\tuniverse, the\t3\nQ42\t\t12\n"},
+		{quickiedata.OutputTable, "item  label          count\n----  -----          -----\nQ1    universe, the  3\nQ42                  12\n"},
+		{quickiedata.OutputJSONLines, "{\"item\":\"Q1\",\"label\":\"universe, the\",\"count\":3}\n{\"item\":\"Q42\",\"label\":null,\"count\":12}\n"},
+		{quickiedata.OutputYAML, "- item: Q1\n  label: \"universe, the\"\n  count: 3\n- item: Q42\n  label: null\n  count: 12\n"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := quickiedata.WriteSPARQLResults(&buf, test.format, testSPARQLSimpleResponse()); err != nil {
+			t.Errorf("%s: %s", test.format, err)
+		} else if buf.String() != test.expected {
+			t.Errorf("%s: expected\n%q\ngot\n%q", test.format, test.expected, buf.String())
+		}
+	}
+
+	if err := quickiedata.WriteSPARQLResults(&bytes.Buffer{}, "xml", testSPARQLSimpleResponse()); err == nil {
+		t.Error("expected error for unknown output format")
+	}
+}
+
+func TestWriteSPARQLResultsWithoutVars(t *testing.T) {
+	resp := testSPARQLSimpleResponse()
+	resp.Vars = nil
+	var buf bytes.Buffer
+	if err := quickiedata.WriteSPARQLResults(&buf, quickiedata.OutputCSV, resp); err != nil {
+		t.Fatal(err)
+	}
+	if header := strings.SplitN(buf.String(), "\n", 2)[0]; header != "count,item,label" {
+		t.Errorf("unexpected header %q", header)
+	}
+}
+
+func TestWriteSearchResults(t *testing.T) {
+	results := []*quickiedata.SearchResult{
+		{ID: "Q2013", Label: "Wikidata", Description: "free knowledge database project"},
+	}
+	var buf bytes.Buffer
+	if err := quickiedata.WriteSearchResults(&buf, quickiedata.OutputTSV, results); err != nil {
+		t.Fatal(err)
+	}
+	expected := "id\tlabel\tdescription\nQ2013\tWikidata\tfree knowledge database project\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q got %q", expected, buf.String())
+	}
+}
+
+func TestWriteEntities(t *testing.T) {
+	item := loadTestSimpleItem(t, "Q2112")
+	// identifiers that look like entity ids are not replaced with labels
+	catalogueCode := "Q183"
+	resp := &quickiedata.GetEntitiesSimpleResponse{
+		Entities: map[string]any{
+			"Q2112": &quickiedata.SimpleItem{
+				Labels: map[string]string{"en": item.Labels["en"]},
+				Claims: map[string][]*quickiedata.SimpleClaim{
+					"P17":  item.Claims["P17"],
+					"P625": item.Claims["P625"],
+					"P528": {{Type: "external", Value: &catalogueCode}},
+				},
+			},
+		},
+	}
+	options := quickiedata.NewFormatOptions()
+	options.LabelResolver = quickiedata.LabelMap{"P17": "country", "Q183": "Germany"}
+
+	var buf bytes.Buffer
+	if err := quickiedata.WriteEntities(&buf, quickiedata.OutputCSV, resp, options); err != nil {
+		t.Fatal(err)
+	}
+	expected := "id,property,value\n" +
+		"Q2112,label:en,Bielefeld\n" +
+		"Q2112,country,Germany\n" +
+		"Q2112,P528,Q183\n" +
+		"Q2112,P625,\"52°1'N, 8°31'E\"\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteSPARQLResultsYAMLQuoting(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"Q42", "Q42"},
+		{"Douglas Adams", "Douglas Adams"},
+		{"São Paulo", "São Paulo"},
+		{"0x1F", `"0x1F"`},
+		{"0o17", `"0o17"`},
+		{"1_000", `"1_000"`},
+		{"+12", `"+12"`},
+		{".inf", `".inf"`},
+		{".nan", `".nan"`},
+		{"2001-12-14", `"2001-12-14"`},
+		{"y", `"y"`},
+		{"N", `"N"`},
+		{"on", `"on"`},
+		{"Off", `"Off"`},
+		{"null", `"null"`},
+		{"~", `"~"`},
+		{"a: b", `"a: b"`},
+		{"- item", `"- item"`},
+		{" padded", `" padded"`},
+		{"", `""`},
+	}
+
+	for _, test := range tests {
+		resp := &quickiedata.SPARQLSimpleResponse{
+			Vars: []string{"value"},
+			Results: []map[string]*quickiedata.SimpleBindingValue{
+				{"value": {Value: test.value}},
+			},
+		}
+		var buf bytes.Buffer
+		if err := quickiedata.WriteSPARQLResults(&buf, quickiedata.OutputYAML, resp); err != nil {
+			t.Fatal(err)
+		}
+		expected := "- value: " + test.expected + "\n"
+		if buf.String() != expected {
+			t.Errorf("%q: expected %q, got %q", test.value, expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package quickiedata
+
+import (
+	"context"
+	"errors"
+)
+
+// DefaultPathDepth is the maximum number of hops in a path if not set
+const DefaultPathDepth = 4
+
+// ErrPathNotFound is returned by FindPath when the entities are not connected within the maximum depth
+var ErrPathNotFound = errors.New("no path found")
+
+// Hop is a step of a path from entity From to entity To along a claim with Property. The claim
+// is on From unless Inverse is set, in which case the claim is on To with From as its value,
+// eg Q5 P31 Q42 with Inverse means Q42 is an instance of Q5.
+type Hop struct {
+	From     string `json:"from"`
+	Property string `json:"property"`
+	To       string `json:"to"`
+	Inverse  bool   `json:"inverse,omitempty"`
+}
+
+type PathOptions struct {
+	// Properties are the claims followed, any property if empty
+	Properties []string
+	// MaxDepth is the maximum number of hops, DefaultPathDepth if 0
+	MaxDepth int
+	// Inverse also follows claims backwards, eg from a parent to a child through father (P22).
+	// Finding claims pointing to an entity needs a ReverseNeighbourSource.
+	Inverse bool
+}
+
+// NewPathOptions creates options for paths of up to DefaultPathDepth hops along any property in either direction
+func NewPathOptions() *PathOptions {
+	return &PathOptions{
+		MaxDepth: DefaultPathDepth,
+		Inverse:  true,
+	}
+}
+
+// pathSide is the search state of one end of a bidirectional search
+type pathSide struct {
+	// hops by entity, toward the start for the forward side and toward the end for the backward side
+	hops     map[string]*Hop
+	depth    map[string]int
+	frontier []string
+	// levels is the number of times the side has been expanded
+	levels int
+}
+
+func newPathSide(id string) *pathSide {
+	return &pathSide{
+		hops:     map[string]*Hop{id: nil},
+		depth:    map[string]int{id: 0},
+		frontier: []string{id},
+	}
+}
+
+// FindPath returns a shortest chain of hops from one entity to another, searching from both
+// ends at once. Each step of the search requests the neighbours of a whole frontier as one
+// batch. If the source cannot find claims pointing to entities, only the start is expanded
+// unless options.Inverse is set. ErrPathNotFound is returned if there is no path within MaxDepth hops.
+func (g *Graph) FindPath(ctx context.Context, from string, to string, options *PathOptions) ([]*Hop, error) {
+	if options == nil {
+		options = NewPathOptions()
+	}
+	maxDepth := options.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultPathDepth
+	}
+	if from == to {
+		return []*Hop{}, nil
+	}
+
+	_, canReverse := g.Source.(ReverseNeighbourSource)
+	// the backward side needs the entities with a hop to it, which are found with
+	// reverse lookups, or through the claims of the entity for inverse hops
+	canExpandBackward := canReverse || options.Inverse
+
+	forward := newPathSide(from)
+	backward := newPathSide(to)
+
+	for depth := 0; depth < maxDepth; depth++ {
+		if len(forward.frontier) == 0 || (canExpandBackward && len(backward.frontier) == 0) {
+			break
+		}
+
+		// expand the smaller frontier, or the side expanded less often if they are the same size
+		expandForward := !canExpandBackward || len(forward.frontier) < len(backward.frontier) ||
+			(len(forward.frontier) == len(backward.frontier) && forward.levels <= backward.levels)
+		side, other := forward, backward
+		if !expandForward {
+			side, other = backward, forward
+		}
+
+		hops, err := g.pathHops(ctx, side.frontier, options, expandForward, canReverse)
+		if err != nil {
+			return nil, err
+		}
+
+		var meet string
+		bestLength := -1
+		var next []string
+		for _, id := range side.frontier {
+			for _, hop := range hops[id] {
+				neighbour := hop.To
+				if !expandForward {
+					neighbour = hop.From
+				}
+				if _, visited := side.hops[neighbour]; visited {
+					continue
+				}
+				side.hops[neighbour] = hop
+				side.depth[neighbour] = side.depth[id] + 1
+				next = append(next, neighbour)
+
+				if otherDepth, met := other.depth[neighbour]; met {
+					length := side.depth[neighbour] + otherDepth
+					if bestLength < 0 || length < bestLength {
+						meet = neighbour
+						bestLength = length
+					}
+				}
+			}
+		}
+		side.frontier = next
+		side.levels++
+
+		if meet != "" {
+			return joinPath(forward, backward, meet), nil
+		}
+	}
+	return nil, ErrPathNotFound
+}
+
+// pathHops returns the hops away from each id for the forward side, or toward each id for the backward side
+func (g *Graph) pathHops(ctx context.Context, ids []string, options *PathOptions, forward bool, canReverse bool) (map[string][]*Hop, error) {
+	output := make(map[string][]*Hop)
+
+	// claims of the entities, a normal hop forward or an inverse hop backward
+	if forward || options.Inverse {
+		edges, err := g.Edges(ctx, ids, options.Properties, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			for _, edge := range edges[id] {
+				hop := &Hop{From: edge.From, Property: edge.Property, To: edge.To}
+				if !forward {
+					hop = &Hop{From: edge.To, Property: edge.Property, To: edge.From, Inverse: true}
+				}
+				output[id] = append(output[id], hop)
+			}
+		}
+	}
+
+	// claims pointing to the entities, an inverse hop forward or a normal hop backward
+	if canReverse && (!forward || options.Inverse) {
+		edges, err := g.Edges(ctx, ids, options.Properties, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			for _, edge := range edges[id] {
+				hop := &Hop{From: edge.From, Property: edge.Property, To: edge.To}
+				if forward {
+					hop = &Hop{From: edge.To, Property: edge.Property, To: edge.From, Inverse: true}
+				}
+				output[id] = append(output[id], hop)
+			}
+		}
+	}
+	return output, nil
+}
+
+// joinPath follows the hops from the meeting entity back to the start and on to the end
+func joinPath(forward *pathSide, backward *pathSide, meet string) []*Hop {
+	var path []*Hop
+	for id := meet; forward.hops[id] != nil; id = forward.hops[id].From {
+		path = append([]*Hop{forward.hops[id]}, path...)
+	}
+	for id := meet; backward.hops[id] != nil; id = backward.hops[id].To {
+		path = append(path, backward.hops[id])
+	}
+	return path
+}
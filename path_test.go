@@ -0,0 +1,143 @@
+package quickiedata_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/rohfle/quickiedata"
+)
+
+// forwardNeighbours hides the reverse lookups of a source
+type forwardNeighbours struct {
+	source quickiedata.NeighbourSource
+	calls  int
+}
+
+func (n *forwardNeighbours) Neighbours(ctx context.Context, ids []string, properties []string) (map[string][]*quickiedata.Edge, error) {
+	n.calls++
+	return n.source.Neighbours(ctx, ids, properties)
+}
+
+func newTestFixtureNeighbours(t *testing.T) quickiedata.ItemNeighbours {
+	items := make(quickiedata.ItemNeighbours)
+	for _, id := range []string{"Q1", "Q2112", "Q217447", "Q22002395", "Q271094", "Q328212", "Q4132785", "Q571", "Q646148"} {
+		items[id] = loadTestSimpleItem(t, id)
+	}
+	return items
+}
+
+func TestFindPath(t *testing.T) {
+	ctx := context.Background()
+	graph := quickiedata.NewGraph(newTestFixtureNeighbours(t))
+
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		options  *quickiedata.PathOptions
+		expected []*quickiedata.Hop
+		err      error
+	}{
+		{
+			name: "shared class",
+			from: "Q328212",
+			to:   "Q646148",
+			expected: []*quickiedata.Hop{
+				{From: "Q328212", Property: "P31", To: "Q5"},
+				{From: "Q5", Property: "P31", To: "Q646148", Inverse: true},
+			},
+		},
+		{
+			name:    "direct claim",
+			from:    "Q4132785",
+			to:      "Q571",
+			options: &quickiedata.PathOptions{Properties: []string{"P31"}},
+			expected: []*quickiedata.Hop{
+				{From: "Q4132785", Property: "P31", To: "Q571"},
+			},
+		},
+		{
+			name:    "inverse claim",
+			from:    "Q571",
+			to:      "Q4132785",
+			options: &quickiedata.PathOptions{Properties: []string{"P31"}, Inverse: true},
+			expected: []*quickiedata.Hop{
+				{From: "Q571", Property: "P31", To: "Q4132785", Inverse: true},
+			},
+		},
+		{
+			name:    "no inverse",
+			from:    "Q571",
+			to:      "Q4132785",
+			options: &quickiedata.PathOptions{Properties: []string{"P31"}},
+			err:     quickiedata.ErrPathNotFound,
+		},
+		{
+			name:    "too deep",
+			from:    "Q328212",
+			to:      "Q646148",
+			options: &quickiedata.PathOptions{MaxDepth: 1, Inverse: true},
+			err:     quickiedata.ErrPathNotFound,
+		},
+		{
+			name:     "same entity",
+			from:     "Q571",
+			to:       "Q571",
+			expected: []*quickiedata.Hop{},
+		},
+	}
+
+	for _, test := range tests {
+		path, err := graph.FindPath(ctx, test.from, test.to, test.options)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.err, err)
+			continue
+		}
+		if diff := deep.Equal(path, test.expected); diff != nil {
+			t.Errorf("%s: %v", test.name, diff)
+		}
+	}
+}
+
+func TestFindPathForwardOnly(t *testing.T) {
+	source := &forwardNeighbours{source: newTestFixtureNeighbours(t)}
+	graph := quickiedata.NewGraph(source)
+
+	options := quickiedata.NewPathOptions()
+	options.Properties = []string{"P31"}
+	path, err := graph.FindPath(context.Background(), "Q22002395", "Q4132785", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*quickiedata.Hop{
+		{From: "Q22002395", Property: "P31", To: "Q571"},
+		{From: "Q571", Property: "P31", To: "Q4132785", Inverse: true},
+	}
+	if diff := deep.Equal(path, expected); diff != nil {
+		t.Error(diff)
+	}
+	// one batch for each end
+	if source.calls != 2 {
+		t.Errorf("expected 2 neighbour requests, got %d", source.calls)
+	}
+}
+
+func TestFindPathIgnoresIdentifiers(t *testing.T) {
+	str := func(s string) *string { return &s }
+	graph := quickiedata.NewGraph(quickiedata.ItemNeighbours{
+		"Q1": {Claims: map[string][]*quickiedata.SimpleClaim{
+			"P528":  {{Type: "external", Value: str("Q2")}},
+			"P1476": {{Type: "string", Value: str("Q2")}},
+		}},
+		"Q2": {Claims: map[string][]*quickiedata.SimpleClaim{
+			"P31": {{Type: "item", Value: str("Q5")}},
+		}},
+	})
+
+	path, err := graph.FindPath(context.Background(), "Q1", "Q2", nil)
+	if !errors.Is(err, quickiedata.ErrPathNotFound) {
+		t.Errorf("expected no path through identifiers, got %v %v", path, err)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rohfle/nicehttp"
 )
@@ -18,6 +20,8 @@ type WikidataClient struct {
 	APIEndpoint    string
 	SPARQLEndpoint string
 	Client         *http.Client
+	// Caching is optional, responses are not cached if nil
+	Caching *CacheSettings
 }
 
 func NewClient(settings *nicehttp.Settings) *WikidataClient {
@@ -37,21 +41,53 @@ func (wd *WikidataClient) GetWithContext(ctx context.Context, url string) (*http
 	return wd.Client.Do(req)
 }
 
-func (wd *WikidataClient) GetEntitiesRaw(ctx context.Context, ids []string, opt *GetEntitiesOptions) ([]byte, error) {
-	url, err := wd.CreateGetEntitiesURL(ids, opt)
+// getAPIWithContext gets an api url and returns the body, or a typed error
+// if the request failed or the api returned an error
+func (wd *WikidataClient) getAPIWithContext(ctx context.Context, url string) ([]byte, error) {
+	resp, err := wd.GetWithContext(ctx, url)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, newHTTPError(resp)
+	}
 
-	resp, err := wd.GetWithContext(ctx, url)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	return io.ReadAll(resp.Body)
+	if err := checkAPIResponse(resp, body); err != nil {
+		return nil, err
+	}
+	return body, nil
 }
 
+func (wd *WikidataClient) GetEntitiesRaw(ctx context.Context, ids []string, opt *GetEntitiesOptions) ([]byte, error) {
+	url, err := wd.CreateGetEntitiesURL(ids, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return wd.getAPIWithContext(ctx, url)
+}
+
+// GetEntities gets entities by id, using the cache for entities that have not changed if caching is enabled
 func (wd *WikidataClient) GetEntities(ctx context.Context, ids []string, options *GetEntitiesOptions) (*GetEntitiesResponse, error) {
+	if wd.Caching != nil && wd.Caching.Cache != nil {
+		if len(ids) == 0 {
+			return nil, errors.New("no ids specified")
+		}
+		if err := ValidateEntityIDs(ids); err != nil {
+			return nil, err
+		}
+		return wd.getEntitiesCached(ctx, ids, options)
+	}
+	return wd.fetchEntities(ctx, ids, options)
+}
+
+// fetchEntities gets entities from the api without using the cache
+func (wd *WikidataClient) fetchEntities(ctx context.Context, ids []string, options *GetEntitiesOptions) (*GetEntitiesResponse, error) {
 	rawBody, err := wd.GetEntitiesRaw(ctx, ids, options)
 	if err != nil {
 		return nil, err
@@ -64,6 +100,7 @@ func (wd *WikidataClient) GetEntities(ctx context.Context, ids []string, options
 	}
 
 	if result.Error != nil {
+		result.Error.ServedBy = result.ServedBy
 		return nil, result.Error
 	}
 
@@ -110,15 +147,21 @@ func (wd *WikidataClient) SearchEntitiesRaw(ctx context.Context, query string, o
 		return nil, err
 	}
 
-	resp, err := wd.GetWithContext(ctx, url)
+	return wd.getAPIWithContext(ctx, url)
+}
+
+func (wd *WikidataClient) SearchEntities(ctx context.Context, query string, options *SearchEntitiesOptions) ([]*SearchResult, error) {
+	result, err := wd.SearchEntitiesPage(ctx, query, options)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	return io.ReadAll(resp.Body)
+
+	return result.Search, nil
 }
 
-func (wd *WikidataClient) SearchEntities(ctx context.Context, query string, options *SearchEntitiesOptions) ([]*SearchResult, error) {
+// SearchEntitiesPage returns a single page of search results including the
+// search-continue offset of the next page
+func (wd *WikidataClient) SearchEntitiesPage(ctx context.Context, query string, options *SearchEntitiesOptions) (*SearchEntitiesResponse, error) {
 	rawBody, err := wd.SearchEntitiesRaw(ctx, query, options)
 	if err != nil {
 		return nil, err
@@ -131,10 +174,112 @@ func (wd *WikidataClient) SearchEntities(ctx context.Context, query string, opti
 	}
 
 	if result.Error != nil {
+		result.Error.ServedBy = result.ServedBy
 		return nil, result.Error
 	}
 
-	return result.Search, nil
+	return &result, nil
+}
+
+// SearchEntitiesAll iterates over search results, following search-continue until
+// maxResults have been returned or there are no more results. A maxResults of 0 or
+// less means no maximum. options.Offset is the starting offset and options.Limit is
+// used as the page size. Iteration stops after the first error.
+func (wd *WikidataClient) SearchEntitiesAll(ctx context.Context, query string, options *SearchEntitiesOptions, maxResults int64) iter.Seq2[*SearchResult, error] {
+	return func(yield func(*SearchResult, error) bool) {
+		pageOptions := *options
+		var count int64
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			page, err := wd.SearchEntitiesPage(ctx, query, &pageOptions)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, result := range page.Search {
+				if !yield(result, nil) {
+					return
+				}
+				count++
+				if maxResults > 0 && count >= maxResults {
+					return
+				}
+			}
+
+			// search-continue is missing on the last page
+			if page.SearchContinue <= pageOptions.Offset || len(page.Search) == 0 {
+				return
+			}
+			pageOptions.Offset = page.SearchContinue
+		}
+	}
+}
+
+// SPARQLQueryPages iterates over the rows of a query, requesting pages of
+// pageOptions.PageSize rows by OFFSET and LIMIT until a short page is returned.
+// query.Offset is the starting offset and query.Limit is the maximum number of rows,
+// or no maximum if 0 or less. The template must not contain its own OFFSET or LIMIT.
+// Iteration stops after the first error.
+func (wd *WikidataClient) SPARQLQueryPages(ctx context.Context, query *SPARQLQuery, options *GetSPARQLQueryOptions, pageOptions *SPARQLPagesOptions) iter.Seq2[map[string]*SimpleBindingValue, error] {
+	return func(yield func(map[string]*SimpleBindingValue, error) bool) {
+		if pageOptions == nil {
+			pageOptions = NewSPARQLPagesOptions()
+		}
+		if pageOptions.PageSize <= 0 {
+			yield(nil, fmt.Errorf("invalid sparql page size %d", pageOptions.PageSize))
+			return
+		}
+
+		queryText := cleanupSPARQL(query.Template)
+		if hasTrailingModifier(queryText, "OFFSET") || hasTrailingModifier(queryText, "LIMIT") {
+			yield(nil, errors.New("sparql query template cannot contain OFFSET or LIMIT when paging"))
+			return
+		}
+		if pageOptions.RequireOrderBy && !hasTrailingModifier(queryText, "ORDER BY") {
+			yield(nil, errors.New("sparql query must contain ORDER BY when paging"))
+			return
+		}
+
+		pageQuery := *query
+		if pageQuery.Offset < 0 {
+			pageQuery.Offset = 0
+		}
+		var count int64
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			pageQuery.Limit = pageOptions.PageSize
+			if query.Limit > 0 && query.Limit-count < pageQuery.Limit {
+				pageQuery.Limit = query.Limit - count
+			}
+
+			page, err := wd.SPARQLQuerySimple(ctx, &pageQuery, options)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, row := range page.Results {
+				if !yield(row, nil) {
+					return
+				}
+			}
+			count += int64(len(page.Results))
+
+			if int64(len(page.Results)) < pageQuery.Limit || (query.Limit > 0 && count >= query.Limit) {
+				return
+			}
+			pageQuery.Offset += int64(len(page.Results))
+		}
+	}
 }
 
 func (wd *WikidataClient) SPARQLQuerySimple(ctx context.Context, query *SPARQLQuery, options *GetSPARQLQueryOptions) (*SPARQLSimpleResponse, error) {
@@ -161,29 +306,135 @@ func (wd *WikidataClient) SPARQLQuery(ctx context.Context, query *SPARQLQuery, o
 	return &result, nil
 }
 
+// SPARQLQueryRaw runs a query and returns the response body. If caching is enabled with
+// a SPARQLTTL, the body is cached by the hash of the rendered query.
 func (wd *WikidataClient) SPARQLQueryRaw(ctx context.Context, query *SPARQLQuery, options *GetSPARQLQueryOptions) ([]byte, error) {
-	sparqlQuery, err := RenderSPARQLQuery(query)
+	var cacheKey string
+	if wd.Caching != nil && wd.Caching.Cache != nil && wd.Caching.SPARQLTTL > 0 {
+		sparqlQuery, err := RenderSPARQLQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		cacheKey = SPARQLCacheKey(sparqlQuery)
+		if body, ok := wd.Caching.Cache.Get(cacheKey); ok {
+			return body, nil
+		}
+	}
+
+	resp, cancel, err := wd.doSPARQLRequest(ctx, query, options)
 	if err != nil {
 		return nil, err
 	}
+	defer cancel()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheKey != "" {
+		wd.Caching.Cache.Set(cacheKey, body, wd.Caching.SPARQLTTL)
+	}
+	return body, nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", wd.SPARQLEndpoint, strings.NewReader(sparqlQuery))
+// SPARQLQueryStream runs a query and returns a stream that decodes the results
+// one row at a time instead of reading the whole response into memory.
+// The stream must be closed once finished with.
+func (wd *WikidataClient) SPARQLQueryStream(ctx context.Context, query *SPARQLQuery, options *GetSPARQLQueryOptions) (*SPARQLStream, error) {
+	resp, cancel, err := wd.doSPARQLRequest(ctx, query, options)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/sparql-query")
-	req.Header.Set("Accept", "application/sparql-results+json")
+	stream, err := NewSPARQLStream(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		cancel()
+		return nil, err
+	}
+	stream.cancel = cancel
+	return stream, nil
+}
+
+// doSPARQLRequest sends a query and returns the successful response. The returned
+// cancel function releases the timeout context and must be called after the body is read.
+func (wd *WikidataClient) doSPARQLRequest(ctx context.Context, query *SPARQLQuery, options *GetSPARQLQueryOptions) (*http.Response, context.CancelFunc, error) {
+	if options == nil {
+		options = NewSPARQLQueryOptions()
+	}
+	if err := options.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	sparqlQuery, err := RenderSPARQLQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if options.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(options.Timeout)*time.Second)
+	}
+
+	req, err := wd.CreateSPARQLRequest(ctx, sparqlQuery, options)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
 
 	resp, err := wd.Client.Do(req)
 	if err != nil {
-		return nil, err
+		cancel()
+		return nil, nil, err
 	}
-	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("request returned status: %s", resp.Status)
+		// read the error body before cancelling the timeout context, which would abort the read
+		serr := newSPARQLError(resp)
+		resp.Body.Close()
+		cancel()
+		return nil, nil, serr
+	}
+	return resp, cancel, nil
+}
+
+// CreateSPARQLRequest creates a request for a rendered sparql query using the method in options
+func (wd *WikidataClient) CreateSPARQLRequest(ctx context.Context, sparqlQuery string, opt *GetSPARQLQueryOptions) (*http.Request, error) {
+	params := url.Values{}
+	if opt.Timeout > 0 {
+		params.Add("timeout", strconv.FormatInt(opt.Timeout, 10))
+	}
+
+	var req *http.Request
+	var err error
+	switch opt.Method {
+	case SPARQLMethodGet:
+		params.Add("query", sparqlQuery)
+		req, err = http.NewRequestWithContext(ctx, "GET", wd.SPARQLEndpoint+"?"+params.Encode(), nil)
+	case SPARQLMethodPostForm:
+		params.Add("query", sparqlQuery)
+		req, err = http.NewRequestWithContext(ctx, "POST", wd.SPARQLEndpoint, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	case SPARQLMethodPostRaw, "":
+		endpoint := wd.SPARQLEndpoint
+		if len(params) > 0 {
+			endpoint += "?" + params.Encode()
+		}
+		req, err = http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(sparqlQuery))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/sparql-query")
+		}
+	default:
+		return nil, fmt.Errorf("invalid sparql method '%s'", opt.Method)
 	}
-	return io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/sparql-results+json")
+	return req, nil
 }
 
 // CreateGetEntitiesURL creates a wikidata api get entries (wbgetentries) query url
@@ -209,12 +460,18 @@ func (wd *WikidataClient) CreateGetEntitiesURL(ids []string, opt *GetEntitiesOpt
 	if len(opt.Languages) > 0 {
 		query.Add("languages", strings.Join(opt.Languages, "|"))
 	}
+	if opt.LanguageFallback {
+		query.Add("languagefallback", "1")
+	}
 	if len(opt.Sitefilter) > 0 {
 		query.Add("sitefilter", strings.Join(opt.Sitefilter, "|"))
 	}
 	if !opt.Redirects {
 		query.Add("redirects", "no")
 	}
+	if opt.MaxLag > 0 {
+		query.Add("maxlag", strconv.Itoa(opt.MaxLag))
+	}
 
 	fullURL := wd.APIEndpoint + "?" + query.Encode()
 	return fullURL, nil
@@ -250,6 +507,10 @@ func (wd *WikidataClient) CreateSearchEntitiesURL(search string, opt *SearchEnti
 		query.Add("format", opt.Format)
 	}
 
+	if opt.MaxLag > 0 {
+		query.Add("maxlag", strconv.Itoa(opt.MaxLag))
+	}
+
 	fullURL := wd.APIEndpoint + "?" + query.Encode()
 	return fullURL, nil
 
@@ -0,0 +1,414 @@
+package quickiedata_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rohfle/quickiedata"
+)
+
+const testSPARQLResponse = `{
+  "head": {"vars": ["item"]},
+  "results": {"bindings": [
+    {"item": {"type": "uri", "value": "http://www.wikidata.org/entity/Q146"}}
+  ]}
+}`
+
+type capturedRequest struct {
+	Method      string
+	ContentType string
+	Query       string
+	Timeout     string
+}
+
+func newTestSPARQLServer(delay time.Duration, captured *capturedRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured.Method = r.Method
+		captured.ContentType = r.Header.Get("Content-Type")
+		captured.Timeout = r.FormValue("timeout")
+		switch captured.ContentType {
+		case "application/sparql-query":
+			body, _ := io.ReadAll(r.Body)
+			captured.Query = string(body)
+		default:
+			captured.Query = r.FormValue("query")
+		}
+
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/sparql-results+json")
+		w.Write([]byte(testSPARQLResponse))
+	}))
+}
+
+func TestSPARQLQueryMethods(t *testing.T) {
+	query := quickiedata.NewSPARQLQuery()
+	query.Template = "SELECT ?item WHERE { ?item wdt:P31 wd:Q146 }"
+	expectedQuery, err := quickiedata.RenderSPARQLQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method      quickiedata.SPARQLMethod
+		httpMethod  string
+		contentType string
+	}{
+		{quickiedata.SPARQLMethodGet, "GET", ""},
+		{quickiedata.SPARQLMethodPostForm, "POST", "application/x-www-form-urlencoded"},
+		{quickiedata.SPARQLMethodPostRaw, "POST", "application/sparql-query"},
+	}
+
+	for _, test := range tests {
+		var captured capturedRequest
+		server := newTestSPARQLServer(0, &captured)
+		wd := &quickiedata.WikidataClient{
+			SPARQLEndpoint: server.URL,
+			Client:         server.Client(),
+		}
+
+		options := quickiedata.NewSPARQLQueryOptions()
+		options.Method = test.method
+		options.Timeout = 30
+		resp, err := wd.SPARQLQuerySimple(context.Background(), query, options)
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: %s", test.method, err)
+			continue
+		}
+
+		if captured.Method != test.httpMethod {
+			t.Errorf("%s: expected http method %s, got %s", test.method, test.httpMethod, captured.Method)
+		}
+		if captured.ContentType != test.contentType {
+			t.Errorf("%s: expected content type %q, got %q", test.method, test.contentType, captured.ContentType)
+		}
+		if captured.Query != expectedQuery {
+			t.Errorf("%s: expected query %q, got %q", test.method, expectedQuery, captured.Query)
+		}
+		if captured.Timeout != "30" {
+			t.Errorf("%s: expected server side timeout 30, got %q", test.method, captured.Timeout)
+		}
+		if len(resp.Results) != 1 || resp.Results[0]["item"].ValueAsString() != "Q146" {
+			t.Errorf("%s: unexpected results %v", test.method, resp.Results)
+		}
+	}
+}
+
+func TestSPARQLQueryTimeout(t *testing.T) {
+	var captured capturedRequest
+	server := newTestSPARQLServer(3*time.Second, &captured)
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		SPARQLEndpoint: server.URL,
+		Client:         server.Client(),
+	}
+
+	query := quickiedata.NewSPARQLQuery()
+	query.Template = "SELECT ?item WHERE { ?item wdt:P31 wd:Q146 }"
+	options := quickiedata.NewSPARQLQueryOptions()
+	options.Timeout = 1
+
+	start := time.Now()
+	_, err := wd.SPARQLQueryRaw(context.Background(), query, options)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("client side timeout not applied, took %s", elapsed)
+	}
+}
+
+func TestSPARQLQueryOptionsValidate(t *testing.T) {
+	options := quickiedata.NewSPARQLQueryOptions()
+	if err := options.Validate(); err != nil {
+		t.Errorf("default options should be valid: %s", err)
+	}
+
+	options.Method = "put"
+	if err := options.Validate(); err == nil {
+		t.Error("expected error for invalid method")
+	}
+
+	options = quickiedata.NewSPARQLQueryOptions()
+	options.Timeout = -5
+	if err := options.Validate(); err == nil {
+		t.Error("expected error for invalid timeout")
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("ids") {
+		case "Q1":
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte("<html><body>Too many requests</body></html>"))
+		case "Q2":
+			w.Header().Set("Retry-After", "3")
+			w.Write([]byte(`{"error":{"code":"maxlag","info":"Waiting for db1: 7 seconds lagged.","host":"db1","lag":7,"*":"See api help"},"servedby":"mw1"}`))
+		default:
+			w.Write([]byte(`{"error":{"code":"no-such-entity","info":"Could not find an entity"},"servedby":"mw2"}`))
+		}
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		APIEndpoint: server.URL,
+		Client:      server.Client(),
+	}
+	options := quickiedata.NewGetEntitiesOptions()
+	ctx := context.Background()
+
+	_, err := wd.GetEntities(ctx, []string{"Q1"}, options)
+	var httpErr *quickiedata.HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != 429 || httpErr.RetryAfter != 5*time.Second || !strings.Contains(httpErr.Body, "Too many") {
+		t.Errorf("unexpected http error %+v", httpErr)
+	}
+	if !quickiedata.IsTemporary(err) {
+		t.Error("429 should be temporary")
+	}
+
+	_, err = wd.GetEntities(ctx, []string{"Q2"}, options)
+	var maxLagErr *quickiedata.MaxLagError
+	if !errors.As(err, &maxLagErr) {
+		t.Fatalf("expected MaxLagError, got %v", err)
+	}
+	if maxLagErr.Lag != 7 || maxLagErr.Host != "db1" || maxLagErr.RetryAfter != 3*time.Second || maxLagErr.ServedBy != "mw1" || maxLagErr.Star != "See api help" {
+		t.Errorf("unexpected maxlag error %+v", maxLagErr)
+	}
+
+	_, err = wd.GetEntities(ctx, []string{"Q3"}, options)
+	var respErr *quickiedata.ResponseError
+	if !errors.As(err, &respErr) {
+		t.Fatalf("expected ResponseError, got %v", err)
+	}
+	if respErr.Code != "no-such-entity" || respErr.ServedBy != "mw2" || quickiedata.IsTemporary(err) {
+		t.Errorf("unexpected response error %+v", respErr)
+	}
+}
+
+func TestSPARQLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.FormValue("query"), "SLOW") {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("SPARQL-QUERY: queryStr=SLOW\njava.util.concurrent.TimeoutException\n\tat java.util.concurrent.FutureTask.get"))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("SPARQL-QUERY: queryStr=BAD\njava.util.concurrent.ExecutionException: org.openrdf.query.MalformedQueryException: Encountered \" <VAR1> \"?x \"\" at line 1, column 8.\n\tat java.util.concurrent.FutureTask.report"))
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		SPARQLEndpoint: server.URL,
+		Client:         server.Client(),
+	}
+	options := quickiedata.NewSPARQLQueryOptions()
+	options.Method = quickiedata.SPARQLMethodGet
+
+	query := quickiedata.NewSPARQLQuery()
+	query.Template = "SELECT ?item WHERE { SLOW }"
+	_, err := wd.SPARQLQuery(context.Background(), query, options)
+	var sparqlErr *quickiedata.SPARQLError
+	if !errors.As(err, &sparqlErr) || sparqlErr.Kind != quickiedata.SPARQLErrorTimeout {
+		t.Errorf("expected sparql timeout error, got %v", err)
+	}
+
+	query.Template = "SELECT ?x ?x WHERE { BAD }"
+	_, err = wd.SPARQLQuery(context.Background(), query, options)
+	if !errors.As(err, &sparqlErr) || sparqlErr.Kind != quickiedata.SPARQLErrorSyntax {
+		t.Fatalf("expected sparql syntax error, got %v", err)
+	}
+	if !strings.HasPrefix(sparqlErr.Message, "Encountered") || sparqlErr.HTTP.StatusCode != 400 {
+		t.Errorf("unexpected syntax error %+v", sparqlErr)
+	}
+}
+
+func TestSPARQLErrorWithoutHTTP(t *testing.T) {
+	err := &quickiedata.SPARQLError{Kind: quickiedata.SPARQLErrorOther}
+	if err.Error() != "sparql query failed" {
+		t.Errorf("unexpected error message %q", err.Error())
+	}
+	if err.Unwrap() != nil {
+		t.Error("expected no wrapped error")
+	}
+}
+
+func TestSPARQLErrorsWithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.(http.Flusher).Flush()
+		// the body arrives after the headers, so it is read after the response is returned
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("SPARQL-QUERY: queryStr=BAD\njava.util.concurrent.ExecutionException: org.openrdf.query.MalformedQueryException: Encountered \" <VAR1> \"?x \"\" at line 1, column 8.\n\tat java.util.concurrent.FutureTask.report"))
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		SPARQLEndpoint: server.URL,
+		Client:         server.Client(),
+	}
+	options := quickiedata.NewSPARQLQueryOptions()
+	options.Timeout = 30
+
+	query := quickiedata.NewSPARQLQuery()
+	query.Template = "SELECT ?x ?x WHERE { BAD }"
+	_, err := wd.SPARQLQuery(context.Background(), query, options)
+	var sparqlErr *quickiedata.SPARQLError
+	if !errors.As(err, &sparqlErr) || sparqlErr.Kind != quickiedata.SPARQLErrorSyntax {
+		t.Fatalf("expected sparql syntax error, got %v", err)
+	}
+	if sparqlErr.HTTP.Body == "" {
+		t.Error("expected error body to be read")
+	}
+}
+
+func TestSearchEntitiesAll(t *testing.T) {
+	const total = 25
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("continue"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var search []map[string]any
+		for i := offset; i < offset+limit && i < total; i++ {
+			search = append(search, map[string]any{"id": fmt.Sprintf("Q%d", i+1)})
+		}
+		response := map[string]any{"search": search, "success": 1}
+		if offset+limit < total {
+			response["search-continue"] = offset + limit
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		APIEndpoint: server.URL,
+		Client:      server.Client(),
+	}
+	options := quickiedata.NewSearchEntitiesOptions()
+	options.Limit = 10
+
+	var ids []string
+	for result, err := range wd.SearchEntitiesAll(context.Background(), "test", options, 0) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, result.ID)
+	}
+	if len(ids) != total || ids[0] != "Q1" || ids[total-1] != "Q25" {
+		t.Errorf("unexpected results %v", ids)
+	}
+
+	ids = nil
+	for result, err := range wd.SearchEntitiesAll(context.Background(), "test", options, 12) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, result.ID)
+	}
+	if len(ids) != 12 {
+		t.Errorf("expected maximum of 12 results, got %d", len(ids))
+	}
+	if options.Offset != 0 {
+		t.Error("options were modified")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range wd.SearchEntitiesAll(ctx, "test", options, 0) {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context canceled, got %v", err)
+		}
+	}
+}
+
+func TestSPARQLQueryPages(t *testing.T) {
+	const total = 25
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		queries = append(queries, string(body))
+		var offset, limit int
+		fmt.Sscanf(string(body)[strings.Index(string(body), "OFFSET"):], "OFFSET %d LIMIT %d", &offset, &limit)
+		var bindings []map[string]any
+		for i := offset; i < offset+limit && i < total; i++ {
+			bindings = append(bindings, map[string]any{
+				"item": map[string]any{"type": "uri", "value": fmt.Sprintf("http://www.wikidata.org/entity/Q%d", i+1)},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"head":    map[string]any{"vars": []string{"item"}},
+			"results": map[string]any{"bindings": bindings},
+		})
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		SPARQLEndpoint: server.URL,
+		Client:         server.Client(),
+	}
+	query := quickiedata.NewSPARQLQuery()
+	query.Template = "SELECT ?item WHERE { ?item wdt:P31 wd:Q146 } ORDER BY ?item"
+	pageOptions := quickiedata.NewSPARQLPagesOptions()
+	pageOptions.PageSize = 10
+	pageOptions.RequireOrderBy = true
+
+	var ids []string
+	for row, err := range wd.SPARQLQueryPages(context.Background(), query, nil, pageOptions) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, row["item"].ValueAsString())
+	}
+	if len(ids) != total || ids[0] != "Q1" || ids[total-1] != "Q25" {
+		t.Errorf("unexpected results %v", ids)
+	}
+	if len(queries) != 3 {
+		t.Errorf("expected 3 pages, got %d", len(queries))
+	}
+
+	queries = nil
+	ids = nil
+	query.Limit = 12
+	for row, err := range wd.SPARQLQueryPages(context.Background(), query, nil, pageOptions) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, row["item"].ValueAsString())
+	}
+	if len(ids) != 12 || !strings.HasSuffix(queries[1], "OFFSET 10 LIMIT 2") {
+		t.Errorf("expected maximum of 12 results, got %d from %v", len(ids), queries)
+	}
+
+	query.Template = "SELECT ?item WHERE { ?item wdt:P31 wd:Q146 }"
+	for _, err := range wd.SPARQLQueryPages(context.Background(), query, nil, pageOptions) {
+		if err == nil || !strings.Contains(err.Error(), "ORDER BY") {
+			t.Errorf("expected ORDER BY error, got %v", err)
+		}
+	}
+
+	query.Template = "SELECT ?item WHERE { ?item wdt:P31 wd:Q146 } ORDER BY ?item LIMIT 5"
+	for _, err := range wd.SPARQLQueryPages(context.Background(), query, nil, pageOptions) {
+		if err == nil {
+			t.Error("expected error for template with LIMIT")
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package quickiedata
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLabelBatchWindow is how long a LabelResolver waits for more ids before requesting a batch
+const DefaultLabelBatchWindow = 10 * time.Millisecond
+
+type LabelResolverOptions struct {
+	// BatchWindow is how long to collect ids before requesting them
+	BatchWindow time.Duration
+	// BatchSize is the maximum number of ids per request, a full batch is requested immediately
+	BatchSize int
+	// Fallback also looks up the fallback languages of the requested language, see LanguageFallbackChain
+	Fallback bool
+}
+
+func NewLabelResolverOptions() *LabelResolverOptions {
+	return &LabelResolverOptions{
+		BatchWindow: DefaultLabelBatchWindow,
+		BatchSize:   MaxEntitiesPerRequest,
+		Fallback:    true,
+	}
+}
+
+// LabelResolver looks up entity labels for many concurrent callers. Ids requested within the
+// batch window are combined into requests of up to BatchSize ids, an id that is already being
+// requested is not requested again, and all labels are cached for the life of the resolver.
+// LabelResolver implements Labeler, so it can be used as FormatOptions.LabelResolver.
+type LabelResolver struct {
+	client  *WikidataClient
+	options *LabelResolverOptions
+
+	mu sync.Mutex
+	// labels by id and language, an empty label means the entity has no label in that language
+	labels map[string]map[string]string
+	// batch collects ids until it is requested
+	batch *labelBatch
+	// inflight holds the batches that have been requested by id
+	inflight map[string][]*labelBatch
+}
+
+type labelBatch struct {
+	ids       []string
+	idSet     map[string]bool
+	languages map[string]bool
+	timer     *time.Timer
+	done      chan struct{}
+	err       error
+}
+
+// NewLabelResolver creates a LabelResolver that gets labels using client
+func NewLabelResolver(client *WikidataClient, options *LabelResolverOptions) *LabelResolver {
+	if options == nil {
+		options = NewLabelResolverOptions()
+	}
+	return &LabelResolver{
+		client:   client,
+		options:  options,
+		labels:   make(map[string]map[string]string),
+		inflight: make(map[string][]*labelBatch),
+	}
+}
+
+// Label returns the label of an entity in a language, following the fallback languages if enabled.
+// An empty string is returned if the entity has no label in any of the languages.
+func (r *LabelResolver) Label(ctx context.Context, id string, language string) (string, error) {
+	labels, err := r.Labels(ctx, []string{id}, []string{language})
+	if err != nil {
+		return "", err
+	}
+	return labels[id], nil
+}
+
+// Labels returns the labels of entities using the first of languages with a label, following the
+// fallback languages of each if enabled. Entities without a label are left out.
+func (r *LabelResolver) Labels(ctx context.Context, ids []string, languages []string) (LabelMap, error) {
+	if err := ValidateEntityIDs(ids); err != nil {
+		return nil, err
+	}
+	chain := r.languageChain(languages)
+
+	for {
+		r.mu.Lock()
+		waitFor := r.enqueue(ids, chain)
+		r.mu.Unlock()
+		if len(waitFor) == 0 {
+			break
+		}
+		for _, batch := range waitFor {
+			select {
+			case <-batch.done:
+				if batch.err != nil {
+					return nil, batch.err
+				}
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	output := make(LabelMap)
+	for _, id := range ids {
+		for _, language := range chain {
+			if label := r.labels[id][language]; label != "" {
+				output[id] = label
+				break
+			}
+		}
+	}
+	return output, nil
+}
+
+// languageChain returns the languages to look up in order without duplicates
+func (r *LabelResolver) languageChain(languages []string) []string {
+	if r.options.Fallback {
+		return LanguageFallbackChains(languages...)
+	}
+	var chain []string
+	for _, language := range languages {
+		language = NormalizeLanguage(language)
+		if language != "" && !ValueInSlice(language, chain) {
+			chain = append(chain, language)
+		}
+	}
+	return chain
+}
+
+// enqueue adds the ids and languages that are not cached or being requested to the current
+// batch, and returns the batches to wait for. The lock must be held.
+func (r *LabelResolver) enqueue(ids []string, languages []string) []*labelBatch {
+	var waitFor []*labelBatch
+	wait := func(batch *labelBatch) {
+		if !ValueInSlice(batch, waitFor) {
+			waitFor = append(waitFor, batch)
+		}
+	}
+
+	for _, id := range ids {
+		var missing []string
+		for _, language := range languages {
+			if _, cached := r.labels[id][language]; !cached {
+				missing = append(missing, language)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		// an inflight batch that covers the missing languages will fill the cache
+		var covered *labelBatch
+		for _, batch := range r.inflight[id] {
+			if batch.covers(missing) {
+				covered = batch
+				break
+			}
+		}
+		if covered != nil {
+			wait(covered)
+			continue
+		}
+
+		if r.batch != nil && !r.batch.idSet[id] && len(r.batch.ids) >= r.options.BatchSize {
+			r.send(r.batch)
+		}
+		if r.batch == nil {
+			batch := &labelBatch{
+				idSet:     make(map[string]bool),
+				languages: make(map[string]bool),
+				done:      make(chan struct{}),
+			}
+			batch.timer = time.AfterFunc(r.options.BatchWindow, func() {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+				if r.batch == batch {
+					r.send(batch)
+				}
+			})
+			r.batch = batch
+		}
+		if !r.batch.idSet[id] {
+			r.batch.idSet[id] = true
+			r.batch.ids = append(r.batch.ids, id)
+		}
+		for _, language := range missing {
+			r.batch.languages[language] = true
+		}
+		wait(r.batch)
+	}
+
+	if r.batch != nil && len(r.batch.ids) >= r.options.BatchSize {
+		r.send(r.batch)
+	}
+	return waitFor
+}
+
+func (b *labelBatch) covers(languages []string) bool {
+	for _, language := range languages {
+		if !b.languages[language] {
+			return false
+		}
+	}
+	return true
+}
+
+// send requests a batch in the background. The lock must be held.
+func (r *LabelResolver) send(batch *labelBatch) {
+	batch.timer.Stop()
+	r.batch = nil
+	for _, id := range batch.ids {
+		r.inflight[id] = append(r.inflight[id], batch)
+	}
+	go r.fetch(batch)
+}
+
+func (r *LabelResolver) fetch(batch *labelBatch) {
+	var languages []string
+	for language := range batch.languages {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	options := NewGetEntitiesOptions()
+	options.Props = []string{"labels"}
+	options.Languages = languages
+	// the batch is shared by many callers, so it is not cancelled with any of their contexts
+	result, err := r.client.GetEntities(context.Background(), batch.ids, options)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		for _, id := range batch.ids {
+			if r.labels[id] == nil {
+				r.labels[id] = make(map[string]string)
+			}
+			var entity *EntityInfo
+			if result != nil {
+				entity = result.Entities[id]
+			}
+			for _, language := range languages {
+				label := ""
+				if entity != nil && entity.Labels[language] != nil {
+					label = entity.Labels[language].Value
+				}
+				r.labels[id][language] = label
+			}
+		}
+	}
+	for _, id := range batch.ids {
+		remaining := r.inflight[id][:0]
+		for _, other := range r.inflight[id] {
+			if other != batch {
+				remaining = append(remaining, other)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(r.inflight, id)
+		} else {
+			r.inflight[id] = remaining
+		}
+	}
+	batch.err = err
+	close(batch.done)
+}
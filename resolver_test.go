@@ -0,0 +1,141 @@
+package quickiedata_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"github.com/rohfle/quickiedata"
+)
+
+func newLabelServer(t *testing.T, labels map[string]map[string]string) (*httptest.Server, *[][]string) {
+	var mu sync.Mutex
+	var requests [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := strings.Split(r.URL.Query().Get("ids"), "|")
+		languages := strings.Split(r.URL.Query().Get("languages"), "|")
+		mu.Lock()
+		requests = append(requests, ids)
+		mu.Unlock()
+
+		entities := make(map[string]any)
+		for _, id := range ids {
+			terms := make(map[string]any)
+			for language, value := range labels[id] {
+				if quickiedata.ValueInSlice(language, languages) {
+					terms[language] = map[string]string{"language": language, "value": value}
+				}
+			}
+			entities[id] = map[string]any{"id": id, "type": "item", "labels": terms}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": 1, "entities": entities})
+	}))
+	return server, &requests
+}
+
+func TestLabelResolverBatching(t *testing.T) {
+	labels := make(map[string]map[string]string)
+	for i := 1; i <= 60; i++ {
+		labels[fmt.Sprintf("Q%d", i)] = map[string]string{"en": fmt.Sprintf("item %d", i)}
+	}
+	server, requests := newLabelServer(t, labels)
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		APIEndpoint: server.URL,
+		Client:      server.Client(),
+	}
+	options := quickiedata.NewLabelResolverOptions()
+	options.BatchWindow = 50 * time.Millisecond
+	resolver := quickiedata.NewLabelResolver(wd, options)
+
+	// every id is requested twice at the same time
+	var wg sync.WaitGroup
+	results := make([]string, 120)
+	for idx := range results {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			label, err := resolver.Label(context.Background(), fmt.Sprintf("Q%d", idx%60+1), "en")
+			if err != nil {
+				t.Error(err)
+			}
+			results[idx] = label
+		}(idx)
+	}
+	wg.Wait()
+
+	for idx, label := range results {
+		if expected := fmt.Sprintf("item %d", idx%60+1); label != expected {
+			t.Errorf("expected %q got %q", expected, label)
+		}
+	}
+
+	requested := 0
+	for _, ids := range *requests {
+		if len(ids) > quickiedata.MaxEntitiesPerRequest {
+			t.Errorf("batch too large: %d ids", len(ids))
+		}
+		requested += len(ids)
+	}
+	if len(*requests) != 2 || requested != 60 {
+		t.Errorf("expected 60 ids in 2 requests, got %d ids in %d requests", requested, len(*requests))
+	}
+
+	// cached labels are not requested again
+	if _, err := resolver.Labels(context.Background(), []string{"Q1", "Q2"}, []string{"en"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("expected cached labels, got %d requests", len(*requests))
+	}
+}
+
+func TestLabelResolverFallback(t *testing.T) {
+	server, _ := newLabelServer(t, map[string]map[string]string{
+		"Q1": {"de-ch": "Universum (ch)", "de": "Universum", "en": "universe"},
+		"Q2": {"de": "Erde", "en": "Earth"},
+		"Q3": {"mul": "Mars", "en": "Mars planet"},
+		"Q4": {"en": "water"},
+		"Q5": {"fr": "humain"},
+	})
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		APIEndpoint: server.URL,
+		Client:      server.Client(),
+	}
+	resolver := quickiedata.NewLabelResolver(wd, nil)
+
+	result, err := resolver.Labels(context.Background(), []string{"Q1", "Q2", "Q3", "Q4", "Q5"}, []string{"de-CH"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := quickiedata.LabelMap{
+		"Q1": "Universum (ch)",
+		"Q2": "Erde",
+		"Q3": "Mars",
+		"Q4": "water",
+	}
+	if diff := deep.Equal(result, expected); diff != nil {
+		t.Error(diff)
+	}
+
+	options := quickiedata.NewLabelResolverOptions()
+	options.Fallback = false
+	resolver = quickiedata.NewLabelResolver(wd, options)
+	label, err := resolver.Label(context.Background(), "Q2", "de-ch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label != "" {
+		t.Errorf("expected no label without fallback, got %q", label)
+	}
+}
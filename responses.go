@@ -3,22 +3,14 @@ package quickiedata
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
-type ResponseError struct {
-	Code  string `json:"code"`
-	Info  string `json:"info"`
-	Extra string `json:"extra"`
-}
-
-func (re *ResponseError) Error() string {
-	return re.Info
-}
-
 type GetEntitiesResponse struct {
 	Entities map[string]*EntityInfo `json:"entities,omitempty"`
 	Success  int64                  `json:"success"`
 	Error    *ResponseError         `json:"error,omitempty"`
+	ServedBy string                 `json:"servedby,omitempty"`
 }
 
 type GetEntitiesSimpleResponse struct {
@@ -53,9 +45,14 @@ type GetEntitySimpleResponse struct {
 }
 
 func (resp *GetEntitiesResponse) Simplify() *GetEntitiesSimpleResponse {
+	return resp.SimplifyWithOptions(NewSimplifyOptions())
+}
+
+// SimplifyWithOptions simplifies the entities with options, see SimplifyEntityWithOptions
+func (resp *GetEntitiesResponse) SimplifyWithOptions(opt *SimplifyOptions) *GetEntitiesSimpleResponse {
 	var output = make(map[string]any)
 	for key, entity := range resp.Entities {
-		simple := SimplifyEntity(entity)
+		simple := SimplifyEntityWithOptions(entity, opt)
 		if simple != nil {
 			output[key] = simple
 		}
@@ -66,7 +63,12 @@ func (resp *GetEntitiesResponse) Simplify() *GetEntitiesSimpleResponse {
 }
 
 func (resp *GetEntityResponse) Simplify() *GetEntitySimpleResponse {
-	simple := SimplifyEntity(resp.Entity)
+	return resp.SimplifyWithOptions(NewSimplifyOptions())
+}
+
+// SimplifyWithOptions simplifies the entity with options, see SimplifyEntityWithOptions
+func (resp *GetEntityResponse) SimplifyWithOptions(opt *SimplifyOptions) *GetEntitySimpleResponse {
+	simple := SimplifyEntityWithOptions(resp.Entity, opt)
 	if simple != nil {
 		return &GetEntitySimpleResponse{
 			Entity: simple,
@@ -161,6 +163,30 @@ func (resp *GetEntitiesSimpleResponse) GetEntityAsLexeme(key string) *SimpleLexe
 	return casted
 }
 
+// GetEntityAsForm returns a form entity, or the form from its lexeme if the lexeme was fetched instead
+func (resp *GetEntitiesSimpleResponse) GetEntityAsForm(key string) *SimpleForm {
+	if resp == nil {
+		return nil
+	}
+	if casted, ok := resp.Entities[key].(*SimpleForm); ok {
+		return casted
+	}
+	lexemeID, _, _ := strings.Cut(key, "-")
+	return resp.GetEntityAsLexeme(lexemeID).GetForm(key)
+}
+
+// GetEntityAsSense returns a sense entity, or the sense from its lexeme if the lexeme was fetched instead
+func (resp *GetEntitiesSimpleResponse) GetEntityAsSense(key string) *SimpleSense {
+	if resp == nil {
+		return nil
+	}
+	if casted, ok := resp.Entities[key].(*SimpleSense); ok {
+		return casted
+	}
+	lexemeID, _, _ := strings.Cut(key, "-")
+	return resp.GetEntityAsLexeme(lexemeID).GetSense(key)
+}
+
 func (resp *GetEntitySimpleResponse) GetEntityAsItem(key string) *SimpleItem {
 	if resp == nil {
 		return nil
@@ -185,6 +211,28 @@ func (resp *GetEntitySimpleResponse) GetEntityAsLexeme(key string) *SimpleLexeme
 	return casted
 }
 
+// GetEntityAsForm returns the entity if it is a form, or the form with the key if the entity is its lexeme
+func (resp *GetEntitySimpleResponse) GetEntityAsForm(key string) *SimpleForm {
+	if resp == nil {
+		return nil
+	}
+	if casted, ok := resp.Entity.(*SimpleForm); ok {
+		return casted
+	}
+	return resp.GetEntityAsLexeme(key).GetForm(key)
+}
+
+// GetEntityAsSense returns the entity if it is a sense, or the sense with the key if the entity is its lexeme
+func (resp *GetEntitySimpleResponse) GetEntityAsSense(key string) *SimpleSense {
+	if resp == nil {
+		return nil
+	}
+	if casted, ok := resp.Entity.(*SimpleSense); ok {
+		return casted
+	}
+	return resp.GetEntityAsLexeme(key).GetSense(key)
+}
+
 type SPARQLResponse struct {
 	Head struct {
 		Vars []string
@@ -197,28 +245,36 @@ type SPARQLResponse struct {
 func (results *SPARQLResponse) Simplify() *SPARQLSimpleResponse {
 	var output []map[string]*SimpleBindingValue
 	for _, binding := range results.Results.Bindings {
-		var newResult = make(map[string]*SimpleBindingValue)
-		for key, bvalue := range binding {
-			if bvalue.Value == nil {
-				continue
-			}
-			val, err := SimplifyBindingValue(bvalue)
-			if err != nil {
-				Log.Printf("error while simplifying %s value %v: %s\n", bvalue.DataType, *bvalue.Value, err)
-				continue
-			} else if val == nil {
-				continue
-			}
-			newResult[key] = val
-		}
-		output = append(output, newResult)
+		output = append(output, SimplifyBinding(binding))
 	}
 	return &SPARQLSimpleResponse{
+		Vars:    results.Head.Vars,
 		Results: output,
 	}
 }
 
+// SimplifyBinding simplifies a single row of sparql results, skipping values that cannot be simplified
+func SimplifyBinding(binding map[string]*BindingValue) map[string]*SimpleBindingValue {
+	var newResult = make(map[string]*SimpleBindingValue)
+	for key, bvalue := range binding {
+		if bvalue.Value == nil {
+			continue
+		}
+		val, err := SimplifyBindingValue(bvalue)
+		if err != nil {
+			Log.Printf("error while simplifying %s value %v: %s\n", bvalue.DataType, *bvalue.Value, err)
+			continue
+		} else if val == nil {
+			continue
+		}
+		newResult[key] = val
+	}
+	return newResult
+}
+
 type SPARQLSimpleResponse struct {
+	// Vars are the variables of the query in the order they were selected
+	Vars    []string
 	Results []map[string]*SimpleBindingValue
 }
 
@@ -3,13 +3,55 @@ package quickiedata
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
+type SimplifyOptions struct {
+	// TruthyOnly keeps preferred claims, or normal claims if a property has no preferred claims
+	TruthyOnly bool
+	// KeepReferences keeps claim references as simplified snaks
+	KeepReferences bool
+	// KeepClaimIDs keeps the statement id of each claim
+	KeepClaimIDs bool
+	// KeepSpecialValues represents somevalue and novalue snaks with the SomeValue and NoValue
+	// sentinel values instead of dropping them
+	KeepSpecialValues bool
+	// Languages limits labels, descriptions, aliases, lemmas, representations and glosses
+	// to these languages. All languages are kept if empty.
+	Languages []string
+	// DropEmpty removes properties with no claims and returns nil for an empty claim map.
+	// Empty labels, descriptions, aliases and sitelinks are always nil.
+	DropEmpty bool
+}
+
+// NewSimplifyOptions returns the options used by SimplifyEntity
+func NewSimplifyOptions() *SimplifyOptions {
+	return &SimplifyOptions{
+		TruthyOnly:        false,
+		KeepReferences:    false,
+		KeepClaimIDs:      false,
+		KeepSpecialValues: false,
+		Languages:         nil,
+		DropEmpty:         false,
+	}
+}
+
+func (opt *SimplifyOptions) keepLanguage(language string) bool {
+	return len(opt.Languages) == 0 || ValueInSlice(language, opt.Languages)
+}
+
 func SimplifyMapOfTermArray(terms map[string][]*Term) map[string][]string {
+	return simplifyMapOfTermArray(terms, NewSimplifyOptions())
+}
+
+func simplifyMapOfTermArray(terms map[string][]*Term, opt *SimplifyOptions) map[string][]string {
 	var output = make(map[string][]string)
 	for key, values := range terms {
+		if !opt.keepLanguage(key) {
+			continue
+		}
 		var valuesOut []string
 		for _, value := range values {
 			valuesOut = append(valuesOut, value.Value)
@@ -23,8 +65,15 @@ func SimplifyMapOfTermArray(terms map[string][]*Term) map[string][]string {
 }
 
 func SimplifyMapOfTerms(terms map[string]*Term) map[string]string {
+	return simplifyMapOfTerms(terms, NewSimplifyOptions())
+}
+
+func simplifyMapOfTerms(terms map[string]*Term, opt *SimplifyOptions) map[string]string {
 	var output = make(map[string]string)
 	for _, value := range terms {
+		if !opt.keepLanguage(value.Language) && (value.ForLanguage == "" || !opt.keepLanguage(value.ForLanguage)) {
+			continue
+		}
 		output[value.Language] = value.Value
 	}
 	if len(output) == 0 {
@@ -34,41 +83,52 @@ func SimplifyMapOfTerms(terms map[string]*Term) map[string]string {
 }
 
 func SimplifyEntity(entity *EntityInfo) any {
+	return SimplifyEntityWithOptions(entity, NewSimplifyOptions())
+}
+
+// SimplifyEntityWithOptions simplifies an entity, with options controlling which claims,
+// snaks and languages are kept
+func SimplifyEntityWithOptions(entity *EntityInfo, opt *SimplifyOptions) any {
+	if opt == nil {
+		opt = NewSimplifyOptions()
+	}
 	switch entity.Type {
 	case "item":
 		return &SimpleItem{
-			Labels:       SimplifyMapOfTerms(entity.Labels),
-			Descriptions: SimplifyMapOfTerms(entity.Descriptions),
-			Aliases:      SimplifyMapOfTermArray(entity.Aliases),
-			Claims:       SimplifyClaims(entity.Claims),
+			Labels:       simplifyMapOfTerms(entity.Labels, opt),
+			Descriptions: simplifyMapOfTerms(entity.Descriptions, opt),
+			Aliases:      simplifyMapOfTermArray(entity.Aliases, opt),
+			Claims:       SimplifyClaimsWithOptions(entity.Claims, opt),
 			Sitelinks:    SimplifySitelinks(entity.Sitelinks),
 		}
 	case "property":
 		return &SimpleProperty{
 			DataType:     entity.DataType,
-			Labels:       SimplifyMapOfTerms(entity.Labels),
-			Descriptions: SimplifyMapOfTerms(entity.Descriptions),
-			Aliases:      SimplifyMapOfTermArray(entity.Aliases),
-			Claims:       SimplifyClaims(entity.Claims),
+			Labels:       simplifyMapOfTerms(entity.Labels, opt),
+			Descriptions: simplifyMapOfTerms(entity.Descriptions, opt),
+			Aliases:      simplifyMapOfTermArray(entity.Aliases, opt),
+			Claims:       SimplifyClaimsWithOptions(entity.Claims, opt),
 		}
 	case "lexeme":
 		return &SimpleLexeme{
 			LexicalCategory: entity.LexicalCategory,
 			Language:        entity.Language,
-			Lemmas:          SimplifyMapOfTerms(entity.Lemmas),
-			Forms:           SimplifyForms(entity.Forms),
-			Senses:          SimplifySenses(entity.Senses),
+			Lemmas:          simplifyMapOfTerms(entity.Lemmas, opt),
+			Forms:           simplifyForms(entity.Forms, opt),
+			Senses:          simplifySenses(entity.Senses, opt),
 		}
 	case "form":
 		return &SimpleForm{
+			ID:                  entity.ID,
 			GrammaticalFeatures: entity.GrammaticalFeatures,
-			Representations:     SimplifyMapOfTerms(entity.Representations),
-			Claims:              SimplifyClaims(entity.Claims),
+			Representations:     simplifyMapOfTerms(entity.Representations, opt),
+			Claims:              SimplifyClaimsWithOptions(entity.Claims, opt),
 		}
 	case "sense":
 		return &SimpleSense{
-			Glosses: SimplifyMapOfTerms(entity.Glosses),
-			Claims:  SimplifyClaims(entity.Claims),
+			ID:      entity.ID,
+			Glosses: simplifyMapOfTerms(entity.Glosses, opt),
+			Claims:  SimplifyClaimsWithOptions(entity.Claims, opt),
 		}
 	default:
 		return nil
@@ -87,29 +147,47 @@ func SimplifySitelinks(sitelinks map[string]*Sitelink) map[string]string {
 }
 
 func SimplifySenses(senses []*Sense) []*SimpleSense {
+	return simplifySenses(senses, NewSimplifyOptions())
+}
+
+func simplifySenses(senses []*Sense, opt *SimplifyOptions) []*SimpleSense {
 	var output []*SimpleSense
 	for _, sense := range senses {
 		output = append(output, &SimpleSense{
-			Glosses: SimplifyMapOfTerms(sense.Glosses),
-			Claims:  SimplifyClaims(sense.Claims),
+			ID:      sense.ID,
+			Glosses: simplifyMapOfTerms(sense.Glosses, opt),
+			Claims:  SimplifyClaimsWithOptions(sense.Claims, opt),
 		})
 	}
 	return output
 }
 
 func SimplifyForms(forms []*Form) []*SimpleForm {
+	return simplifyForms(forms, NewSimplifyOptions())
+}
+
+func simplifyForms(forms []*Form, opt *SimplifyOptions) []*SimpleForm {
 	var output []*SimpleForm
 	for _, form := range forms {
 		output = append(output, &SimpleForm{
-			Representations:     SimplifyMapOfTerms(form.Representations),
+			ID:                  form.ID,
+			Representations:     simplifyMapOfTerms(form.Representations, opt),
 			GrammaticalFeatures: form.GrammaticalFeatures,
-			Claims:              SimplifyClaims(form.Claims),
+			Claims:              SimplifyClaimsWithOptions(form.Claims, opt),
 		})
 	}
 	return output
 }
 
 func SimplifyClaims(claimMap map[string][]*Claim) map[string][]*SimpleClaim {
+	return SimplifyClaimsWithOptions(claimMap, NewSimplifyOptions())
+}
+
+// SimplifyClaimsWithOptions simplifies claims, ordered by preferred, normal then deprecated rank
+func SimplifyClaimsWithOptions(claimMap map[string][]*Claim, opt *SimplifyOptions) map[string][]*SimpleClaim {
+	if opt == nil {
+		opt = NewSimplifyOptions()
+	}
 	var output = make(map[string][]*SimpleClaim)
 
 	for key, claims := range claimMap {
@@ -117,7 +195,7 @@ func SimplifyClaims(claimMap map[string][]*Claim) map[string][]*SimpleClaim {
 		var newClaims []*SimpleClaim
 		var deprecatedClaims []*SimpleClaim
 		for _, claim := range claims {
-			mainSnak := SimplifySnak(claim.MainSnak)
+			mainSnak := SimplifySnakWithOptions(claim.MainSnak, opt)
 			if mainSnak == nil {
 				continue
 			}
@@ -125,8 +203,18 @@ func SimplifyClaims(claimMap map[string][]*Claim) map[string][]*SimpleClaim {
 				Type:  mainSnak.Type,
 				Value: mainSnak.Value,
 			}
+			if opt.KeepClaimIDs {
+				simpleClaim.ID = claim.ID
+			}
 			if len(claim.Qualifiers) > 0 {
-				simpleClaim.Qualifiers = SimplifySnaks(claim.Qualifiers)
+				simpleClaim.Qualifiers = SimplifySnaksWithOptions(claim.Qualifiers, opt)
+			}
+			if opt.KeepReferences {
+				for _, reference := range claim.References {
+					if snaks := SimplifySnaksWithOptions(reference.Snaks, opt); snaks != nil {
+						simpleClaim.References = append(simpleClaim.References, snaks)
+					}
+				}
 			}
 
 			switch claim.Rank {
@@ -142,20 +230,42 @@ func SimplifyClaims(claimMap map[string][]*Claim) map[string][]*SimpleClaim {
 			}
 		}
 
-		output[key] = preferredClaims
-		output[key] = append(output[key], newClaims...)
-		output[key] = append(output[key], deprecatedClaims...)
+		var keyClaims []*SimpleClaim
+		if opt.TruthyOnly {
+			if len(preferredClaims) > 0 {
+				keyClaims = preferredClaims
+			} else {
+				keyClaims = newClaims
+			}
+		} else {
+			keyClaims = append(preferredClaims, newClaims...)
+			keyClaims = append(keyClaims, deprecatedClaims...)
+		}
+
+		if opt.DropEmpty && len(keyClaims) == 0 {
+			continue
+		}
+		output[key] = keyClaims
+	}
+
+	if opt.DropEmpty && len(output) == 0 {
+		return nil
 	}
 	return output
 }
 
 func SimplifySnak(snak *Snak) *SimpleSnakValue {
-	if snak.SnakType != "value" {
-		return nil
-	}
+	return SimplifySnakWithOptions(snak, NewSimplifyOptions())
+}
 
+// SimplifySnakWithOptions simplifies a snak, returning nil for somevalue and novalue
+// snaks unless KeepSpecialValues is set
+func SimplifySnakWithOptions(snak *Snak, opt *SimplifyOptions) *SimpleSnakValue {
+	if opt == nil {
+		opt = NewSimplifyOptions()
+	}
 	stype := snak.DataType
-	if stype == "" {
+	if stype == "" && snak.DataValue != nil {
 		stype = snak.DataValue.Type
 	}
 
@@ -163,6 +273,19 @@ func SimplifySnak(snak *Snak) *SimpleSnakValue {
 		stype = alt
 	}
 
+	if snak.SnakType != "value" {
+		// special values keep the datatype of the property, only the value is marked
+		if opt.KeepSpecialValues {
+			switch SnakType(snak.SnakType) {
+			case SnakTypeSomeValue:
+				return &SimpleSnakValue{Type: stype, Value: SomeValue}
+			case SnakTypeNoValue:
+				return &SimpleSnakValue{Type: stype, Value: NoValue}
+			}
+		}
+		return nil
+	}
+
 	return &SimpleSnakValue{
 		Type:  stype,
 		Value: ParseClaim(snak.DataValue),
@@ -170,13 +293,20 @@ func SimplifySnak(snak *Snak) *SimpleSnakValue {
 }
 
 func SimplifySnaks(snakMap map[string][]*Snak) map[string][]*SimpleSnakValue {
+	return SimplifySnaksWithOptions(snakMap, NewSimplifyOptions())
+}
+
+func SimplifySnaksWithOptions(snakMap map[string][]*Snak, opt *SimplifyOptions) map[string][]*SimpleSnakValue {
+	if opt == nil {
+		opt = NewSimplifyOptions()
+	}
 	var output = make(map[string][]*SimpleSnakValue)
 
 	for key, snaks := range snakMap {
 		var newSnaks []*SimpleSnakValue
 		for _, snak := range snaks {
-			if snak.SnakType == "value" {
-				newSnaks = append(newSnaks, SimplifySnak(snak))
+			if simple := SimplifySnakWithOptions(snak, opt); simple != nil {
+				newSnaks = append(newSnaks, simple)
 			}
 		}
 		if len(newSnaks) > 0 {
@@ -232,7 +362,7 @@ func ParseClaim(dv *SnakValue) any {
 			Precision:     value.Precision,
 			// Fix month / date in wikidata returned date strings
 			// Notes: may still not be valid ISO strings as years might be very big
-			Time:     strings.ReplaceAll(value.Time, "-00", "-01"),
+			Time:     fixUnknownMonthAndDay(value.Time),
 			Timezone: value.Timezone,
 		}
 	default:
@@ -242,6 +372,24 @@ func ParseClaim(dv *SnakValue) any {
 	}
 }
 
+var unknownMonthAndDay = regexp.MustCompile(`^([+-]?\d+)-(\d{2})-(\d{2})T`)
+
+// fixUnknownMonthAndDay replaces a month or day of 00 with 01, leaving the year untouched
+func fixUnknownMonthAndDay(s string) string {
+	match := unknownMonthAndDay.FindStringSubmatchIndex(s)
+	if match == nil {
+		return s
+	}
+	fixed := []byte(s)
+	for _, group := range []int{2, 3} {
+		start := match[group*2]
+		if s[start:start+2] == "00" {
+			fixed[start+1] = '1'
+		}
+	}
+	return string(fixed)
+}
+
 func SimplifySPARQLDataType(s string) string {
 	return strings.ToLower(strings.TrimPrefix(s, "http://www.w3.org/2001/XMLSchema#"))
 }
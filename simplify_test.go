@@ -133,3 +133,98 @@ func getTestEntitySimplifyCouples(baseDir string) ([][2]string, error) {
 
 	return toReturn, nil
 }
+
+func loadTestEntity(t *testing.T, id string) *quickiedata.EntityInfo {
+	data, err := os.ReadFile("testdata/simplify/" + id + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entity quickiedata.EntityInfo
+	if err := json.Unmarshal(data, &entity); err != nil {
+		t.Fatal(err)
+	}
+	return &entity
+}
+
+func TestSimplifyEntityWithOptions(t *testing.T) {
+	entity := loadTestEntity(t, "Q4115189")
+
+	options := quickiedata.NewSimplifyOptions()
+	options.TruthyOnly = true
+	options.KeepClaimIDs = true
+	item := quickiedata.SimplifyEntityWithOptions(entity, options).(*quickiedata.SimpleItem)
+	if claims := item.GetClaims("P135"); len(claims) != 1 || claims[0].Rank != "preferred" || claims[0].ID == "" {
+		t.Errorf("expected single preferred claim with id, got %v", claims)
+	}
+	if claims := item.GetClaims("P569"); len(claims) != 1 || claims[0].Rank != "" {
+		t.Errorf("expected single normal claim, got %v", claims)
+	}
+
+	options = quickiedata.NewSimplifyOptions()
+	options.Languages = []string{"de"}
+	options.DropEmpty = true
+	item = quickiedata.SimplifyEntityWithOptions(entity, options).(*quickiedata.SimpleItem)
+	if item.Labels != nil || item.Descriptions != nil || item.Aliases != nil {
+		t.Errorf("expected no terms, got %v %v %v", item.Labels, item.Descriptions, item.Aliases)
+	}
+	emptyClaims := map[string][]*quickiedata.Claim{"P31": {{MainSnak: &quickiedata.Snak{SnakType: "novalue"}}}}
+	if claims := quickiedata.SimplifyClaimsWithOptions(emptyClaims, options); claims != nil {
+		t.Errorf("expected empty claims to be dropped, got %v", claims)
+	}
+
+	// nil options behave like the defaults
+	if diff := deep.Equal(quickiedata.SimplifyClaimsWithOptions(entity.Claims, nil), quickiedata.SimplifyClaims(entity.Claims)); diff != nil {
+		t.Error(diff)
+	}
+	for property, claims := range entity.Claims {
+		if diff := deep.Equal(quickiedata.SimplifySnakWithOptions(claims[0].MainSnak, nil), quickiedata.SimplifySnak(claims[0].MainSnak)); diff != nil {
+			t.Errorf("%s: %v", property, diff)
+		}
+		if diff := deep.Equal(quickiedata.SimplifySnaksWithOptions(claims[0].Qualifiers, nil), quickiedata.SimplifySnaks(claims[0].Qualifiers)); diff != nil {
+			t.Errorf("%s: %v", property, diff)
+		}
+	}
+
+	entity = loadTestEntity(t, "Q22002395")
+	item = quickiedata.SimplifyEntity(entity).(*quickiedata.SimpleItem)
+	authors := len(item.GetClaims("P50"))
+	if item.GetClaim("P50").References != nil {
+		t.Error("references should be dropped by default")
+	}
+
+	options = quickiedata.NewSimplifyOptions()
+	options.KeepSpecialValues = true
+	options.KeepReferences = true
+	item = quickiedata.SimplifyEntityWithOptions(entity, options).(*quickiedata.SimpleItem)
+	var someValues int
+	for _, claim := range item.GetClaims("P50") {
+		if claim.IsSomeValue() {
+			someValues++
+			if claim.Type != "item" {
+				t.Errorf("expected somevalue claim to keep the item datatype, got %q", claim.Type)
+			}
+		}
+	}
+	if someValues == 0 || len(item.GetClaims("P50")) != authors+someValues {
+		t.Errorf("expected somevalue claims to be kept, got %d of %d", someValues, len(item.GetClaims("P50")))
+	}
+	if len(item.GetClaim("P50").References) == 0 {
+		t.Error("expected references to be kept")
+	}
+
+	// special values and references survive a json round trip
+	data, err := json.Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"value":{"snaktype":"somevalue"}`) {
+		t.Error("expected somevalue to be marked in json")
+	}
+	var roundTrip quickiedata.SimpleItem
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(&roundTrip, item); diff != nil {
+		t.Error(diff)
+	}
+}
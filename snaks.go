@@ -3,7 +3,6 @@ package quickiedata
 import (
 	"encoding/json"
 	"fmt"
-	"strconv"
 	"strings"
 )
 
@@ -96,28 +95,38 @@ func (s *SnakValueTime) GetYear() *int {
 	if s == nil {
 		return nil
 	}
-	yearStr := strings.SplitN(strings.TrimPrefix(s.Time, "+"), "-", 2)[0]
-	if val, err := strconv.Atoi(yearStr); err == nil {
-		return &val
+	parsed, err := ParseTime(s)
+	if err != nil {
+		return nil
 	}
-	// its possible that the year might not fit in an int32
-	// so might need to process the field yourself
-	return nil
+	val := int(parsed.Year)
+	if int64(val) != parsed.Year {
+		// the year does not fit in an int, use ParseTime instead
+		return nil
+	}
+	return &val
 }
 
 func (s *SnakValueTime) GetDate() *string {
 	if s == nil {
 		return nil
 	}
+	parsed, err := ParseTime(s)
+	if err != nil {
+		return nil
+	}
 
-	date := strings.SplitN(strings.TrimPrefix(s.Time, "+"), "T", 2)[0]
-	if s.Precision >= 11 {
+	year := fmt.Sprintf("%04d", parsed.Year)
+	if parsed.Year < 0 {
+		year = fmt.Sprintf("-%04d", -parsed.Year)
+	}
+	if parsed.Precision >= PrecisionDay {
+		date := fmt.Sprintf("%s-%02d-%02d", year, parsed.Month, parsed.Day)
 		return &date
 	}
 	// if there is less precision than a certain date, just return the year.
 	// is there a better alternative?
-	if s.Precision >= 9 {
-		year := strings.SplitN(date, "-", 2)[0]
+	if parsed.Precision >= PrecisionYear {
 		return &year
 	}
 	return nil
@@ -194,11 +203,37 @@ func (sv *SnakValue) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(peek.Value, sv.Value)
 }
 
+// SpecialSnakValue is the simplified value of a somevalue or novalue snak
+type SpecialSnakValue string
+
+const (
+	// SomeValue is used for snaks where a value exists but is unknown
+	SomeValue SpecialSnakValue = "somevalue"
+	// NoValue is used for snaks where there is explicitly no value
+	NoValue SpecialSnakValue = "novalue"
+)
+
+// MarshalJSON writes the special value as {"snaktype":"somevalue"} so it cannot be mistaken
+// for a string value of the same datatype
+func (ssv SpecialSnakValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"snaktype": string(ssv)})
+}
+
 type SimpleSnakValue struct {
 	Type  string `json:"type"`
 	Value any    `json:"value"`
 }
 
+// IsSomeValue reports whether the snak has an unknown value, only set when simplified with KeepSpecialValues
+func (sv *SimpleSnakValue) IsSomeValue() bool {
+	return sv != nil && sv.Value == SomeValue
+}
+
+// IsNoValue reports whether the snak explicitly has no value, only set when simplified with KeepSpecialValues
+func (sv *SimpleSnakValue) IsNoValue() bool {
+	return sv != nil && sv.Value == NoValue
+}
+
 func (sv *SimpleSnakValue) ValueAsString() *string {
 	if sv == nil {
 		return nil
@@ -253,6 +288,13 @@ func (sv *SimpleSnakValue) UnmarshalJSON(data []byte) error {
 func unmarshalSimpleSnakValue(stype string, data []byte) (any, error) {
 	var value any
 
+	var special struct {
+		SnakType SpecialSnakValue `json:"snaktype"`
+	}
+	if json.Unmarshal(data, &special) == nil && (special.SnakType == SomeValue || special.SnakType == NoValue) {
+		return special.SnakType, nil
+	}
+
 	switch stype {
 	case "string", "external", "item", "url", "property", "lexeme", "media", "geoshape", "musical", "form", "sense", "tabular":
 		s := ""
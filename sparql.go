@@ -3,9 +3,11 @@ package quickiedata
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -102,22 +104,31 @@ func RenderSPARQLQuery(query *SPARQLQuery) (string, error) {
 		queryText = insertStatementsInWhere(queryText, strings.Join(statements, " "))
 	}
 
-	lastIndexCurly := strings.LastIndex(queryText, "}")
-	trailingOptions := ""
-	if lastIndexCurly >= 0 {
-		trailingOptions = strings.ToLower(queryText[lastIndexCurly:])
-	}
-	// probably should replace the limit and offset in the query or error
-	// but for now this is good enough
-	if query.Offset >= 0 && strings.Index(trailingOptions, "offset ") <= 0 {
+	// offset and limit in the template take precedence over the query fields
+	if query.Offset >= 0 && !hasTrailingModifier(queryText, "OFFSET") {
 		queryText += fmt.Sprintf(" OFFSET %d", query.Offset)
 	}
-	if query.Limit > 0 && strings.Index(trailingOptions, "limit ") <= 0 {
+	if query.Limit > 0 && !hasTrailingModifier(queryText, "LIMIT") {
 		queryText += fmt.Sprintf(" LIMIT %d", query.Limit)
 	}
 	return queryText, nil
 }
 
+var trailingModifiers = map[string]*regexp.Regexp{
+	"OFFSET":   regexp.MustCompile(`(?i)\bOFFSET\s+\d+`),
+	"LIMIT":    regexp.MustCompile(`(?i)\bLIMIT\s+\d+`),
+	"ORDER BY": regexp.MustCompile(`(?i)\bORDER\s+BY\b`),
+}
+
+// hasTrailingModifier checks if a solution modifier appears after the final closing curly bracket
+func hasTrailingModifier(queryText string, modifier string) bool {
+	lastIndexCurly := strings.LastIndex(queryText, "}")
+	if lastIndexCurly < 0 {
+		return false
+	}
+	return trailingModifiers[modifier].MatchString(queryText[lastIndexCurly:])
+}
+
 func renderSPARQLStatement(name string, value any) (string, error) {
 	// validate key is valid
 	if !ValidSPARQLVariableName.MatchString(name) {
@@ -151,11 +162,29 @@ func renderSPARQLStatement(name string, value any) (string, error) {
 		return fmt.Sprintf(`VALUES ?%s { %s }`, name, strings.Join(values, " ")), nil
 	case int, int32, int64:
 		return fmt.Sprintf(`BIND( %d as ?%s)`, v, name), nil
+	case float32:
+		return renderSPARQLDouble(name, float64(v), 32)
+	case float64:
+		return renderSPARQLDouble(name, v, 64)
+	case *SnakValueGlobeCoordinate:
+		if v == nil {
+			return "", fmt.Errorf("nil coordinate for sparql variable '%s'", name)
+		}
+		// for use with the wikibase:around and wikibase:box services
+		return fmt.Sprintf(`BIND( "%s"^^geo:wktLiteral as ?%s)`, v.WKT(), name), nil
 	default:
 		return "", fmt.Errorf("unhandled %s datatype", reflect.TypeOf(v))
 	}
 }
 
+// renderSPARQLDouble binds a float as an xsd:double literal, so whole numbers are not read as integers
+func renderSPARQLDouble(name string, v float64, bitSize int) (string, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "", fmt.Errorf("invalid number %v for sparql variable '%s'", v, name)
+	}
+	return fmt.Sprintf(`BIND( "%s"^^xsd:double as ?%s)`, strconv.FormatFloat(v, 'g', -1, bitSize), name), nil
+}
+
 func insertStatementsInWhere(query string, statementBlock string) string {
 	// search for WHERE {
 	clauses := regexp.MustCompile(`(?i)WHERE\s*{`).FindAllStringIndex(query, -1)
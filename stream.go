@@ -0,0 +1,164 @@
+package quickiedata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// SPARQLStream decodes sparql json results one binding at a time using json.Decoder tokens,
+// so large result sets do not need to be held in memory
+type SPARQLStream struct {
+	// Vars are the variable names from head.vars. They are read before the first row
+	// unless the endpoint sends the results before the head, in which case they are
+	// only available after the last row.
+	Vars []string
+
+	body   io.Reader
+	dec    *json.Decoder
+	depth  int // 1 when in the top level object, 2 when in the results object
+	done   bool
+	cancel func()
+}
+
+// NewSPARQLStream reads r until the start of results.bindings
+func NewSPARQLStream(r io.Reader) (*SPARQLStream, error) {
+	stream := &SPARQLStream{
+		body: r,
+		dec:  json.NewDecoder(r),
+	}
+	if err := stream.expectDelim('{'); err != nil {
+		return nil, err
+	}
+	stream.depth = 1
+	if err := stream.advance(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// Next returns the next binding, or io.EOF when there are no more bindings
+func (s *SPARQLStream) Next() (map[string]*BindingValue, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+	if !s.dec.More() {
+		// end of bindings, read the rest of the response
+		if err := s.expectDelim(']'); err != nil {
+			return nil, err
+		}
+		if err := s.advance(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	var binding map[string]*BindingValue
+	if err := s.dec.Decode(&binding); err != nil {
+		return nil, err
+	}
+	return binding, nil
+}
+
+// Bindings iterates over the remaining bindings. Iteration stops after the first error.
+func (s *SPARQLStream) Bindings() iter.Seq2[map[string]*BindingValue, error] {
+	return func(yield func(map[string]*BindingValue, error) bool) {
+		for {
+			binding, err := s.Next()
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(binding, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Rows iterates over the remaining bindings simplified the same way as SPARQLResponse.Simplify.
+// Iteration stops after the first error.
+func (s *SPARQLStream) Rows() iter.Seq2[map[string]*SimpleBindingValue, error] {
+	return func(yield func(map[string]*SimpleBindingValue, error) bool) {
+		for binding, err := range s.Bindings() {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(SimplifyBinding(binding), nil) {
+				return
+			}
+		}
+	}
+}
+
+// Close closes the underlying response body if there is one
+func (s *SPARQLStream) Close() error {
+	var err error
+	if closer, ok := s.body.(io.Closer); ok {
+		err = closer.Close()
+	}
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return err
+}
+
+// advance reads object keys until the start of the bindings array or the end of the response
+func (s *SPARQLStream) advance() error {
+	for s.depth > 0 {
+		if !s.dec.More() {
+			if err := s.expectDelim('}'); err != nil {
+				return err
+			}
+			s.depth--
+			continue
+		}
+
+		token, err := s.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+
+		switch {
+		case s.depth == 1 && key == "head":
+			var head struct {
+				Vars []string `json:"vars"`
+			}
+			if err := s.dec.Decode(&head); err != nil {
+				return err
+			}
+			s.Vars = head.Vars
+		case s.depth == 1 && key == "results":
+			if err := s.expectDelim('{'); err != nil {
+				return err
+			}
+			s.depth = 2
+		case s.depth == 2 && key == "bindings":
+			return s.expectDelim('[')
+		default:
+			// skip unused values such as boolean or link
+			var skip json.RawMessage
+			if err := s.dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+	s.done = true
+	return nil
+}
+
+func (s *SPARQLStream) expectDelim(delim json.Delim) error {
+	token, err := s.dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("unexpected token %v in sparql results, expected %v", token, delim)
+	}
+	return nil
+}
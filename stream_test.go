@@ -0,0 +1,101 @@
+package quickiedata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/rohfle/quickiedata"
+)
+
+const testSPARQLStreamResponse = `{
+  "head": {"vars": ["item", "itemLabel", "count", "flag", "ratio", "born"]},
+  "results": {"bindings": [
+    {
+      "item": {"type": "uri", "value": "http://www.wikidata.org/entity/Q146"},
+      "itemLabel": {"type": "literal", "value": "house cat", "xml:lang": "en"},
+      "count": {"type": "literal", "value": "42", "datatype": "http://www.w3.org/2001/XMLSchema#integer"},
+      "flag": {"type": "literal", "value": "true", "datatype": "http://www.w3.org/2001/XMLSchema#boolean"}
+    },
+    {
+      "item": {"type": "uri", "value": "http://www.wikidata.org/entity/Q1185550"},
+      "ratio": {"type": "literal", "value": "0.5", "datatype": "http://www.w3.org/2001/XMLSchema#float"},
+      "born": {"type": "literal", "value": "1990-01-01T00:00:00Z", "datatype": "http://www.w3.org/2001/XMLSchema#dateTime"},
+      "blank": {"type": "bnode", "value": "t123"}
+    }
+  ]}
+}`
+
+func TestSPARQLStreamMatchesSimplify(t *testing.T) {
+	var response quickiedata.SPARQLResponse
+	if err := json.Unmarshal([]byte(testSPARQLStreamResponse), &response); err != nil {
+		t.Fatal(err)
+	}
+	expected := response.Simplify().Results
+
+	// the head may also come after the results
+	reordered := `{"results": {"bindings": [` +
+		strings.SplitN(strings.SplitN(testSPARQLStreamResponse, `"bindings": [`, 2)[1], "]}", 2)[0] +
+		`]}, "head": {"vars": ["item"]}}`
+
+	for _, body := range []string{testSPARQLStreamResponse, reordered} {
+		stream, err := quickiedata.NewSPARQLStream(strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows []map[string]*quickiedata.SimpleBindingValue
+		for row, err := range stream.Rows() {
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows = append(rows, row)
+		}
+		if diff := deep.Equal(rows, expected); diff != nil {
+			t.Error(diff)
+		}
+		if len(stream.Vars) == 0 || stream.Vars[0] != "item" {
+			t.Errorf("head vars not decoded: %v", stream.Vars)
+		}
+	}
+}
+
+func TestSPARQLQueryStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testSPARQLStreamResponse))
+	}))
+	defer server.Close()
+
+	wd := &quickiedata.WikidataClient{
+		SPARQLEndpoint: server.URL,
+		Client:         server.Client(),
+	}
+	query := quickiedata.NewSPARQLQuery()
+	query.Template = "SELECT ?item WHERE { ?item wdt:P31 wd:Q146 }"
+
+	stream, err := wd.SPARQLQueryStream(context.Background(), query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	if len(stream.Vars) != 6 {
+		t.Errorf("expected vars before first row, got %v", stream.Vars)
+	}
+	var count int
+	for row, err := range stream.Rows() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if row["item"] == nil {
+			t.Error("missing item in row")
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("expected 2 rows, got %d", count)
+	}
+}
@@ -100,10 +100,19 @@ var ListOfEntityDataTypes = []DataType{
 func DataTypeIsSimple(dtype string) bool {
 	return ValueInSlice(DataType(dtype), ListOfSimpleDataTypes)
 }
+
+// ListOfSimpleEntityTypes are the types of simplified claims and snaks with an entity id as value
+var ListOfSimpleEntityTypes = []string{"item", "property", "lexeme", "form", "sense"}
+
 func DataTypeIsEntity(dtype string) bool {
 	return ValueInSlice(DataType(dtype), ListOfEntityDataTypes)
 }
 
+// SimpleTypeIsEntity reports whether the type of a simplified claim or snak has an entity id as value
+func SimpleTypeIsEntity(stype string) bool {
+	return ValueInSlice(stype, ListOfSimpleEntityTypes)
+}
+
 func ValueInSlice[T comparable](needle T, haystack []T) bool {
 	for _, hay := range haystack {
 		if hay == needle {
@@ -136,6 +145,15 @@ func (n *NumberPlus) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON adds back the "+" prefix that wikidata uses for positive numbers
+func (n NumberPlus) MarshalJSON() ([]byte, error) {
+	str := string(n)
+	if str != "" && !strings.HasPrefix(str, "-") {
+		str = "+" + str
+	}
+	return json.Marshal(str)
+}
+
 type Sitelink struct {
 	Site   string   `json:"site"`
 	Title  string   `json:"title"`
@@ -146,6 +164,8 @@ type Sitelink struct {
 type Term struct {
 	Language string `json:"language"`
 	Value    string `json:"value"`
+	// ForLanguage is the requested language when the term is a fallback, see GetEntitiesOptions.LanguageFallback
+	ForLanguage string `json:"for-language,omitempty"`
 }
 
 type Reference struct {
@@ -0,0 +1,218 @@
+package quickiedata
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Dimension is the physical quantity measured by a unit
+type Dimension string
+
+const (
+	DimensionLength      Dimension = "length"
+	DimensionMass        Dimension = "mass"
+	DimensionTime        Dimension = "time"
+	DimensionArea        Dimension = "area"
+	DimensionVolume      Dimension = "volume"
+	DimensionTemperature Dimension = "temperature"
+	DimensionSpeed       Dimension = "speed"
+	DimensionDataSize    Dimension = "data size"
+)
+
+// UnitDefinition describes how to convert a unit to the base unit of its dimension,
+// where base = amount * Factor + Offset. Factor and Offset are exact rational numbers
+// such as "0.3048" or "5/9".
+type UnitDefinition struct {
+	Dimension Dimension
+	Factor    string
+	Offset    string
+}
+
+// LookupBaseUnits is the unit each dimension is converted to by ToSI.
+// Data size has no SI unit so bytes are used.
+var LookupBaseUnits = map[Dimension]string{
+	DimensionLength:      "Q11573",  // m
+	DimensionMass:        "Q11570",  // kg
+	DimensionTime:        "Q11574",  // s
+	DimensionArea:        "Q25343",  // m²
+	DimensionVolume:      "Q25517",  // m³
+	DimensionTemperature: "Q11579",  // K
+	DimensionSpeed:       "Q182429", // m/s
+	DimensionDataSize:    "Q8799",   // B
+}
+
+// LookupUnitDefinitions are the units that can be converted. Lengths in LookupLengthUnitToMM
+// and times in LookupTimeUnitToSeconds are added from those tables.
+var LookupUnitDefinitions = map[string]UnitDefinition{
+	// length
+	"Q200323": {Dimension: DimensionLength, Factor: "0.1"},               // dm
+	"Q175821": {Dimension: DimensionLength, Factor: "0.000001"},          // μm
+	"Q178674": {Dimension: DimensionLength, Factor: "0.000000001"},       // nm
+	"Q192274": {Dimension: DimensionLength, Factor: "0.000000000001"},    // pm
+	"Q81454":  {Dimension: DimensionLength, Factor: "0.0000000001"},      // Å
+	"Q93318":  {Dimension: DimensionLength, Factor: "1852"},              // nmi
+	"Q1811":   {Dimension: DimensionLength, Factor: "149597870700"},      // ua
+	"Q531":    {Dimension: DimensionLength, Factor: "9460730472580800"},  // ly
+	"Q12129":  {Dimension: DimensionLength, Factor: "30856775814913673"}, // pc
+	// mass
+	"Q11570":  {Dimension: DimensionMass, Factor: "1"},              // kg
+	"Q41803":  {Dimension: DimensionMass, Factor: "0.001"},          // g
+	"Q191118": {Dimension: DimensionMass, Factor: "1000"},           // t
+	"Q100995": {Dimension: DimensionMass, Factor: "0.45359237"},     // lb
+	"Q48013":  {Dimension: DimensionMass, Factor: "0.028349523125"}, // oz
+	"Q261247": {Dimension: DimensionMass, Factor: "0.0002"},         // ct
+	// time
+	"Q573": {Dimension: DimensionTime, Factor: "86400"},    // d
+	"Q577": {Dimension: DimensionTime, Factor: "31557600"}, // a, julian year
+	// area
+	"Q25343":  {Dimension: DimensionArea, Factor: "1"},              // m²
+	"Q712226": {Dimension: DimensionArea, Factor: "1000000"},        // km²
+	"Q35852":  {Dimension: DimensionArea, Factor: "10000"},          // ha
+	"Q185078": {Dimension: DimensionArea, Factor: "100"},            // a, are
+	"Q81292":  {Dimension: DimensionArea, Factor: "4046.8564224"},   // acre
+	"Q232291": {Dimension: DimensionArea, Factor: "2589988.110336"}, // mi²
+	"Q857027": {Dimension: DimensionArea, Factor: "0.09290304"},     // ft²
+	// volume
+	"Q25517":   {Dimension: DimensionVolume, Factor: "1"},              // m³
+	"Q11582":   {Dimension: DimensionVolume, Factor: "0.001"},          // l
+	"Q4243638": {Dimension: DimensionVolume, Factor: "1000000000"},     // km³
+	"Q178506":  {Dimension: DimensionVolume, Factor: "0.158987294928"}, // bbl
+	// temperature
+	"Q11579": {Dimension: DimensionTemperature, Factor: "1"},                        // K
+	"Q25267": {Dimension: DimensionTemperature, Factor: "1", Offset: "273.15"},      // °C
+	"Q42289": {Dimension: DimensionTemperature, Factor: "5/9", Offset: "45967/180"}, // °F
+	// speed
+	"Q182429": {Dimension: DimensionSpeed, Factor: "1"},       // m/s
+	"Q180154": {Dimension: DimensionSpeed, Factor: "5/18"},    // km/h
+	"Q211256": {Dimension: DimensionSpeed, Factor: "0.44704"}, // mph
+	"Q128822": {Dimension: DimensionSpeed, Factor: "463/900"}, // kn
+	// data size
+	"Q8799":  {Dimension: DimensionDataSize, Factor: "1"},          // B
+	"Q8805":  {Dimension: DimensionDataSize, Factor: "1/8"},        // bit
+	"Q79726": {Dimension: DimensionDataSize, Factor: "1000"},       // kB
+	"Q79735": {Dimension: DimensionDataSize, Factor: "1000000"},    // MB
+	"Q79738": {Dimension: DimensionDataSize, Factor: "1000000000"}, // GB
+}
+
+func init() {
+	for unit, mm := range LookupLengthUnitToMM {
+		factor, _ := new(big.Rat).SetString(strconv.FormatFloat(mm, 'f', -1, 64))
+		factor.Quo(factor, big.NewRat(1000, 1))
+		LookupUnitDefinitions[unit] = UnitDefinition{Dimension: DimensionLength, Factor: factor.RatString()}
+	}
+	for unit, seconds := range LookupTimeUnitToSeconds {
+		LookupUnitDefinitions[unit] = UnitDefinition{Dimension: DimensionTime, Factor: strconv.Itoa(seconds)}
+	}
+}
+
+// UnitConversionError is returned when a quantity cannot be converted to another unit
+type UnitConversionError struct {
+	From string
+	To   string
+	// Reason explains why, eg that a unit is not known or the dimensions are different
+	Reason string
+}
+
+func (ue *UnitConversionError) Error() string {
+	return fmt.Sprintf("cannot convert unit '%s' to '%s': %s", ue.From, ue.To, ue.Reason)
+}
+
+// normaliseUnit returns the id of a unit, or a blank string for no unit
+func normaliseUnit(unit string) string {
+	unit = GetWikidataIDFromURL(unit)
+	if unit == "1" || unit == "Q199" {
+		return ""
+	}
+	return unit
+}
+
+// GetUnitDimension returns the dimension of a unit, or false if the unit is not known
+func GetUnitDimension(unit string) (Dimension, bool) {
+	definition, exists := LookupUnitDefinitions[GetWikidataIDFromURL(unit)]
+	return definition.Dimension, exists
+}
+
+// ToSI converts the quantity to the base unit of its dimension, see LookupBaseUnits
+func (q *SnakValueQuantity) ToSI() (*SnakValueQuantity, error) {
+	unit := GetWikidataIDFromURL(q.Unit)
+	definition, exists := LookupUnitDefinitions[unit]
+	if !exists {
+		return nil, &UnitConversionError{From: unit, To: "SI", Reason: "unknown unit"}
+	}
+	return q.ConvertTo(LookupBaseUnits[definition.Dimension])
+}
+
+// ConvertTo converts the quantity and its bounds to another unit, given as an id such as Q11573.
+// The unit of the result is an entity url, or "1" for unitless quantities, as in quantities returned by the api.
+// Amounts are converted with arbitrary precision so exact conversions stay exact.
+func (q *SnakValueQuantity) ConvertTo(unit string) (*SnakValueQuantity, error) {
+	from := normaliseUnit(q.Unit)
+	to := normaliseUnit(unit)
+	if from == to {
+		output := *q
+		output.Unit = "1"
+		if to != "" {
+			output.Unit = "http://www.wikidata.org/entity/" + to
+		}
+		return &output, nil
+	}
+
+	fromDefinition, exists := LookupUnitDefinitions[from]
+	if !exists {
+		return nil, &UnitConversionError{From: from, To: to, Reason: fmt.Sprintf("unknown unit '%s'", from)}
+	}
+	toDefinition, exists := LookupUnitDefinitions[to]
+	if !exists {
+		return nil, &UnitConversionError{From: from, To: to, Reason: fmt.Sprintf("unknown unit '%s'", to)}
+	}
+	if fromDefinition.Dimension != toDefinition.Dimension {
+		return nil, &UnitConversionError{
+			From:   from,
+			To:     to,
+			Reason: fmt.Sprintf("%s cannot be converted to %s", fromDefinition.Dimension, toDefinition.Dimension),
+		}
+	}
+
+	convert := func(n NumberPlus) (NumberPlus, error) {
+		if n == "" {
+			return "", nil
+		}
+		value, err := n.Rat()
+		if err != nil {
+			return "", err
+		}
+		// to base unit, then from base unit
+		value.Add(value.Mul(value, fromDefinition.factor()), fromDefinition.offset())
+		value.Quo(value.Sub(value, toDefinition.offset()), toDefinition.factor())
+		return NumberPlus(formatRat(value)), nil
+	}
+
+	var err error
+	output := &SnakValueQuantity{Unit: "http://www.wikidata.org/entity/" + to}
+	if output.Amount, err = convert(q.Amount); err != nil {
+		return nil, err
+	}
+	if output.UpperBound, err = convert(q.UpperBound); err != nil {
+		return nil, err
+	}
+	if output.LowerBound, err = convert(q.LowerBound); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (ud UnitDefinition) factor() *big.Rat {
+	return parseRat(ud.Factor, 1)
+}
+
+func (ud UnitDefinition) offset() *big.Rat {
+	return parseRat(ud.Offset, 0)
+}
+
+func parseRat(s string, fallback int64) *big.Rat {
+	if value, ok := new(big.Rat).SetString(s); ok {
+		return value
+	}
+	return big.NewRat(fallback, 1)
+}
@@ -0,0 +1,105 @@
+package quickiedata_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rohfle/quickiedata"
+)
+
+func TestSnakValueQuantityConvertTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    *quickiedata.SnakValueQuantity
+		unit     string
+		expected *quickiedata.SnakValueQuantity
+	}{
+		{
+			name:     "feet to metres with bounds",
+			value:    &quickiedata.SnakValueQuantity{Amount: "100", UpperBound: "101", LowerBound: "99", Unit: "http://www.wikidata.org/entity/Q3710"},
+			unit:     "Q11573",
+			expected: &quickiedata.SnakValueQuantity{Amount: "30.48", UpperBound: "30.7848", LowerBound: "30.1752", Unit: "http://www.wikidata.org/entity/Q11573"},
+		},
+		{
+			name:     "yards to feet",
+			value:    &quickiedata.SnakValueQuantity{Amount: "1", Unit: "Q482798"},
+			unit:     "http://www.wikidata.org/entity/Q3710",
+			expected: &quickiedata.SnakValueQuantity{Amount: "3", Unit: "http://www.wikidata.org/entity/Q3710"},
+		},
+		{
+			name:     "celsius to fahrenheit",
+			value:    &quickiedata.SnakValueQuantity{Amount: "100", Unit: "Q25267"},
+			unit:     "Q42289",
+			expected: &quickiedata.SnakValueQuantity{Amount: "212", Unit: "http://www.wikidata.org/entity/Q42289"},
+		},
+		{
+			name:     "hectares to square kilometres",
+			value:    &quickiedata.SnakValueQuantity{Amount: "250", Unit: "Q35852"},
+			unit:     "Q712226",
+			expected: &quickiedata.SnakValueQuantity{Amount: "2.5", Unit: "http://www.wikidata.org/entity/Q712226"},
+		},
+		{
+			name:     "arbitrary precision",
+			value:    &quickiedata.SnakValueQuantity{Amount: "12345678901234567890.123", Unit: "Q828224"},
+			unit:     "Q11573",
+			expected: &quickiedata.SnakValueQuantity{Amount: "12345678901234567890123", Unit: "http://www.wikidata.org/entity/Q11573"},
+		},
+		{
+			name:     "repeating decimal",
+			value:    &quickiedata.SnakValueQuantity{Amount: "10", Unit: "Q182429"},
+			unit:     "Q180154",
+			expected: &quickiedata.SnakValueQuantity{Amount: "36", Unit: "http://www.wikidata.org/entity/Q180154"},
+		},
+		{
+			name:     "same unit given as id",
+			value:    &quickiedata.SnakValueQuantity{Amount: "5", UpperBound: "6", Unit: "Q11573"},
+			unit:     "http://www.wikidata.org/entity/Q11573",
+			expected: &quickiedata.SnakValueQuantity{Amount: "5", UpperBound: "6", Unit: "http://www.wikidata.org/entity/Q11573"},
+		},
+		{
+			name:     "unitless",
+			value:    &quickiedata.SnakValueQuantity{Amount: "5", Unit: "http://www.wikidata.org/entity/Q199"},
+			unit:     "1",
+			expected: &quickiedata.SnakValueQuantity{Amount: "5", Unit: "1"},
+		},
+	}
+
+	for _, test := range tests {
+		result, err := test.value.ConvertTo(test.unit)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+		} else if *result != *test.expected {
+			t.Errorf("%s: expected %+v got %+v", test.name, test.expected, result)
+		}
+	}
+}
+
+func TestSnakValueQuantityToSI(t *testing.T) {
+	result, err := (&quickiedata.SnakValueQuantity{Amount: "1", Unit: "Q25235"}).ToSI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Amount != "3600" || result.Unit != "http://www.wikidata.org/entity/Q11574" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	result, err = (&quickiedata.SnakValueQuantity{Amount: "1", Unit: "Q7727"}).ConvertTo("Q25235")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Amount != "0.016666666666666666667" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestSnakValueQuantityConvertToError(t *testing.T) {
+	var convErr *quickiedata.UnitConversionError
+	_, err := (&quickiedata.SnakValueQuantity{Amount: "1", Unit: "http://www.wikidata.org/entity/Q11573"}).ConvertTo("Q11570")
+	if !errors.As(err, &convErr) || convErr.From != "Q11573" || convErr.To != "Q11570" {
+		t.Errorf("expected unit conversion error, got %v", err)
+	}
+	_, err = (&quickiedata.SnakValueQuantity{Amount: "1"}).ToSI()
+	if !errors.As(err, &convErr) {
+		t.Errorf("expected unit conversion error for unitless quantity, got %v", err)
+	}
+}
@@ -0,0 +1,327 @@
+package quickiedata
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var snakValueTimeType = reflect.TypeOf(&SnakValueTime{})
+var snakValueQuantityType = reflect.TypeOf(&SnakValueQuantity{})
+var snakValueGlobeCoordinateType = reflect.TypeOf(&SnakValueGlobeCoordinate{})
+
+// UnmarshalItem fills the fields of the struct pointed to by v from item using `wd` field tags.
+// The first part of the tag selects what to read:
+//
+//	wd:"P569"             claim values of a property
+//	wd:"label,en"         label in a language, also description and alias
+//	wd:"sitelink,enwiki"  sitelink title for a site
+//
+// Slice fields get every value and other fields get the first value. The option "first" only
+// keeps the first value of a slice field and "all" requires a slice field. Claim tags also take
+// "preferred", "normal", "deprecated" or "truthy" to filter by rank. Without a rank option
+// deprecated claims are skipped. Truthy keeps preferred claims, or normal claims if there are
+// no preferred claims.
+//
+// Fields can be string, []string, time.Time, *SnakValueTime, *SnakValueQuantity,
+// *SnakValueGlobeCoordinate, slices of these, or structs for claims with qualifiers. Fields of
+// a qualifier struct are tagged with the qualifier property, or wd:"value" and wd:"rank" for the
+// value and rank of the claim itself.
+func UnmarshalItem(item *SimpleItem, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unmarshal target must be a pointer to a struct, not %T", v)
+	}
+	if item == nil {
+		return nil
+	}
+
+	structValue := rv.Elem()
+	for _, field := range reflect.VisibleFields(structValue.Type()) {
+		tag, ok := parseWikidataTag(field)
+		if !ok {
+			continue
+		}
+		target := structValue.FieldByIndex(field.Index)
+
+		var err error
+		switch tag.name {
+		case "label":
+			err = setSnakField(target, termValues(item.Labels, tag.key), tag)
+		case "description":
+			err = setSnakField(target, termValues(item.Descriptions, tag.key), tag)
+		case "alias":
+			var values []any
+			for _, alias := range item.Aliases[tag.key] {
+				values = append(values, alias)
+			}
+			err = setSnakField(target, values, tag)
+		case "sitelink":
+			err = setSnakField(target, termValues(item.Sitelinks, tag.key), tag)
+		default:
+			if !IsEntityID(tag.name) {
+				return fmt.Errorf("field %s: invalid wd tag %q", field.Name, field.Tag.Get("wd"))
+			}
+			err = setClaimsField(target, tag.filterClaims(item.GetClaims(tag.name)), tag)
+		}
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+type wikidataTag struct {
+	name  string
+	key   string
+	first bool
+	all   bool
+	ranks []string
+	// truthy keeps preferred claims if there are any, otherwise normal claims
+	truthy bool
+}
+
+func parseWikidataTag(field reflect.StructField) (*wikidataTag, bool) {
+	raw := field.Tag.Get("wd")
+	if !field.IsExported() || raw == "" || raw == "-" {
+		return nil, false
+	}
+	parts := strings.Split(raw, ",")
+	tag := &wikidataTag{name: parts[0]}
+	for _, option := range parts[1:] {
+		switch option {
+		case "all":
+			tag.all = true
+		case "first":
+			tag.first = true
+		case "truthy":
+			tag.truthy = true
+		case "preferred", "normal", "deprecated":
+			tag.ranks = append(tag.ranks, option)
+		default:
+			tag.key = option
+		}
+	}
+	return tag, true
+}
+
+func (tag *wikidataTag) filterClaims(claims []*SimpleClaim) []*SimpleClaim {
+	var filtered []*SimpleClaim
+	var hasPreferred bool
+	for _, claim := range claims {
+		rank := claim.Rank
+		if rank == "" {
+			rank = string(RankNormal)
+		}
+		if rank == string(RankPreferred) {
+			hasPreferred = true
+		}
+		if len(tag.ranks) > 0 {
+			if ValueInSlice(rank, tag.ranks) {
+				filtered = append(filtered, claim)
+			}
+		} else if rank != string(RankDeprecated) {
+			filtered = append(filtered, claim)
+		}
+	}
+
+	if tag.truthy && hasPreferred {
+		var preferred []*SimpleClaim
+		for _, claim := range filtered {
+			if claim.Rank == string(RankPreferred) {
+				preferred = append(preferred, claim)
+			}
+		}
+		return preferred
+	}
+	return filtered
+}
+
+func termValues(terms map[string]string, key string) []any {
+	value, exists := terms[key]
+	if !exists {
+		return nil
+	}
+	return []any{value}
+}
+
+// setClaimsField sets a field from claims, using qualifier structs if the field holds structs
+func setClaimsField(field reflect.Value, claims []*SimpleClaim, tag *wikidataTag) error {
+	elemType := field.Type()
+	if elemType.Kind() == reflect.Slice {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct || elemType == timeType || reflect.PointerTo(elemType) == snakValueTimeType ||
+		reflect.PointerTo(elemType) == snakValueQuantityType || reflect.PointerTo(elemType) == snakValueGlobeCoordinateType {
+		var values []any
+		for _, claim := range claims {
+			values = append(values, claim.Value)
+		}
+		return setSnakField(field, values, tag)
+	}
+
+	claims, err := limitValues(field, claims, tag)
+	if err != nil {
+		return err
+	}
+	var structs []reflect.Value
+	for _, claim := range claims {
+		target := reflect.New(elemType)
+		if err := unmarshalQualifiers(claim, target.Elem()); err != nil {
+			return err
+		}
+		structs = append(structs, target)
+	}
+	return setFieldValues(field, structs, func(target reflect.Value, value reflect.Value) error {
+		if target.Kind() == reflect.Pointer {
+			target.Set(value)
+		} else {
+			target.Set(value.Elem())
+		}
+		return nil
+	})
+}
+
+// limitValues returns only the first value unless the field is a slice without the first option
+func limitValues[T any](field reflect.Value, values []T, tag *wikidataTag) ([]T, error) {
+	isSlice := field.Kind() == reflect.Slice
+	if tag.all && !isSlice {
+		return nil, fmt.Errorf("all option requires a slice field, not %s", field.Type())
+	}
+	if (tag.first || !isSlice) && len(values) > 1 {
+		return values[:1], nil
+	}
+	return values, nil
+}
+
+func unmarshalQualifiers(claim *SimpleClaim, structValue reflect.Value) error {
+	for _, field := range reflect.VisibleFields(structValue.Type()) {
+		tag, ok := parseWikidataTag(field)
+		if !ok {
+			continue
+		}
+		target := structValue.FieldByIndex(field.Index)
+
+		var err error
+		switch tag.name {
+		case "value":
+			err = setSnakField(target, []any{claim.Value}, tag)
+		case "rank":
+			rank := claim.Rank
+			if rank == "" {
+				rank = string(RankNormal)
+			}
+			err = setSnakField(target, []any{rank}, tag)
+		default:
+			if !IsEntityID(tag.name) {
+				return fmt.Errorf("field %s: invalid wd tag %q", field.Name, field.Tag.Get("wd"))
+			}
+			var values []any
+			for _, qualifier := range claim.GetQualifiers(tag.name) {
+				values = append(values, qualifier.Value)
+			}
+			err = setSnakField(target, values, tag)
+		}
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// setSnakField sets a field from simplified snak values
+func setSnakField(field reflect.Value, values []any, tag *wikidataTag) error {
+	values, err := limitValues(field, usableSnakValues(field, values), tag)
+	if err != nil {
+		return err
+	}
+	var converted []reflect.Value
+	for _, value := range values {
+		converted = append(converted, reflect.ValueOf(value))
+	}
+	return setFieldValues(field, converted, setSnakValue)
+}
+
+// usableSnakValues drops empty values, and somevalue / novalue unless the field can hold them
+func usableSnakValues(field reflect.Value, values []any) []any {
+	target := field.Type()
+	if target.Kind() == reflect.Slice {
+		target = target.Elem()
+	}
+	var output []any
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		if _, special := value.(SpecialSnakValue); special && !reflect.TypeOf(value).AssignableTo(target) {
+			continue
+		}
+		output = append(output, value)
+	}
+	return output
+}
+
+func setFieldValues(field reflect.Value, values []reflect.Value, set func(reflect.Value, reflect.Value) error) error {
+	if len(values) == 0 {
+		return nil
+	}
+	// []byte would never hold a snak value so all slices are treated as multiple values
+	if field.Kind() == reflect.Slice {
+		output := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for idx, value := range values {
+			if err := set(output.Index(idx), value); err != nil {
+				return err
+			}
+		}
+		field.Set(output)
+		return nil
+	}
+	return set(field, values[0])
+}
+
+func setSnakValue(field reflect.Value, value reflect.Value) error {
+	if !value.IsValid() {
+		return nil
+	}
+	// values from simplified claims are usually pointers, eg *string
+	if value.Kind() == reflect.Pointer && value.Type() != field.Type() {
+		if value.IsNil() {
+			return nil
+		}
+		if value.Type().Elem().Kind() == reflect.String {
+			value = value.Elem()
+		}
+	}
+
+	switch {
+	case value.Type() == field.Type():
+		field.Set(value)
+	case field.Type() == timeType:
+		t, ok := value.Interface().(*SnakValueTime)
+		if !ok {
+			return fmt.Errorf("cannot use %s value as time", value.Type())
+		}
+		parsed, err := t.ToTime()
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsed))
+	case field.Kind() == reflect.String:
+		switch v := value.Interface().(type) {
+		case string:
+			field.SetString(v)
+		case *SnakValueTime:
+			field.SetString(v.Time)
+		case *SnakValueQuantity:
+			field.SetString(v.Amount.String())
+		default:
+			return fmt.Errorf("cannot use %s value as string", value.Type())
+		}
+	default:
+		return fmt.Errorf("cannot use %s value as %s", value.Type(), field.Type())
+	}
+	return nil
+}
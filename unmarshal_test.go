@@ -0,0 +1,114 @@
+package quickiedata_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rohfle/quickiedata"
+)
+
+type testPopulation struct {
+	Amount      *quickiedata.SnakValueQuantity `wd:"value"`
+	Rank        string                         `wd:"rank"`
+	PointInTime time.Time                      `wd:"P585"`
+}
+
+type testCity struct {
+	Label       string                                `wd:"label,en"`
+	Sitelink    string                                `wd:"sitelink,enwiki"`
+	InstanceOf  []string                              `wd:"P31,all"`
+	FirstType   []string                              `wd:"P31,first"`
+	Country     string                                `wd:"P17"`
+	Coordinate  *quickiedata.SnakValueGlobeCoordinate `wd:"P625"`
+	Area        *quickiedata.SnakValueQuantity        `wd:"P2046"`
+	Population  testPopulation                        `wd:"P1082,truthy"`
+	Populations []*testPopulation                     `wd:"P1082"`
+	Missing     string                                `wd:"P999999"`
+	Untagged    string
+}
+
+func loadTestSimpleItem(t *testing.T, id string) *quickiedata.SimpleItem {
+	data, err := os.ReadFile("testdata/simplify/" + id + ".simple.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var item quickiedata.SimpleItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		t.Fatal(err)
+	}
+	return &item
+}
+
+func TestUnmarshalItem(t *testing.T) {
+	item := loadTestSimpleItem(t, "Q2112")
+
+	var city testCity
+	if err := quickiedata.UnmarshalItem(item, &city); err != nil {
+		t.Fatal(err)
+	}
+
+	if city.Label != "Bielefeld" || city.Sitelink != "Bielefeld" || city.Country != "Q183" {
+		t.Errorf("unexpected terms or claims %+v", city)
+	}
+	if len(city.InstanceOf) != 4 || city.InstanceOf[0] != "Q22865" || len(city.FirstType) != 1 {
+		t.Errorf("unexpected instance of %v %v", city.InstanceOf, city.FirstType)
+	}
+	if city.Coordinate == nil || city.Coordinate.Globe != "Q2" || city.Area == nil || city.Area.Unit != "Q712226" {
+		t.Errorf("unexpected coordinate %v or area %v", city.Coordinate, city.Area)
+	}
+	if city.Population.Rank != "preferred" || city.Population.Amount.Amount != "329327" ||
+		!city.Population.PointInTime.Equal(time.Date(2014, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected population %+v", city.Population)
+	}
+	if len(city.Populations) != len(item.GetClaims("P1082")) || city.Populations[1].Rank != "normal" {
+		t.Errorf("unexpected populations %v", city.Populations)
+	}
+
+	var invalid struct {
+		Country string `wd:"P17,all"`
+	}
+	if err := quickiedata.UnmarshalItem(item, &invalid); err == nil {
+		t.Error("expected error for all option on a string field")
+	}
+
+	var mismatch struct {
+		Country *quickiedata.SnakValueQuantity `wd:"P17"`
+	}
+	if err := quickiedata.UnmarshalItem(item, &mismatch); err == nil {
+		t.Error("expected error for mismatched field type")
+	}
+}
+
+func TestUnmarshalItemSpecialValues(t *testing.T) {
+	str := func(s string) *string { return &s }
+	item := &quickiedata.SimpleItem{
+		Claims: map[string][]*quickiedata.SimpleClaim{
+			"P17":  {{Type: "item", Value: nil}, {Type: "item", Value: str("Q183")}},
+			"P19":  {{Type: "item", Value: quickiedata.SomeValue}},
+			"P570": {{Type: "item", Value: quickiedata.NoValue}},
+			"P569": {{Type: "time", Value: &quickiedata.SnakValueTime{Time: "-0100-07-12T00:00:00Z", Precision: 11}}},
+		},
+	}
+
+	var person struct {
+		Country     string                       `wd:"P17"`
+		Countries   []string                     `wd:"P17"`
+		BirthPlace  string                       `wd:"P19"`
+		DeathPlace  quickiedata.SpecialSnakValue `wd:"P570"`
+		DateOfBirth time.Time                    `wd:"P569"`
+	}
+	if err := quickiedata.UnmarshalItem(item, &person); err != nil {
+		t.Fatal(err)
+	}
+	if person.Country != "Q183" || len(person.Countries) != 1 {
+		t.Errorf("expected empty values to be skipped, got %q %v", person.Country, person.Countries)
+	}
+	if person.BirthPlace != "" || person.DeathPlace != quickiedata.NoValue {
+		t.Errorf("unexpected special values %q %q", person.BirthPlace, person.DeathPlace)
+	}
+	if person.DateOfBirth.Year() != -99 {
+		t.Errorf("expected bce date of birth, got %s", person.DateOfBirth)
+	}
+}